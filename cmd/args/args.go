@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // GitCommit the git commit hash at compile time
@@ -49,6 +50,7 @@ type IP4SubnetDescribe struct {
 	IP            string `arg:"-i,--ip" help:""`
 	Bits          int    `arg:"-b,--bits" help:""`
 	SecondaryBits int    `arg:"-s,--secondary-bits" help:""`
+	Provider      string `arg:"--provider" help:"aws, azure or gcp, to apply its reserved addresses and limits"`
 }
 
 // IP4SubnetRanges for calls to get list of subnet ranges
@@ -57,6 +59,9 @@ type IP4SubnetRanges struct {
 	Bits          int    `arg:"-b,--bits" help:""`
 	SecondaryBits int    `arg:"-s,--secondary-bits" help:""`
 	Pretty        bool   `arg:"-p,--pretty" help:""`
+	Offset        int    `arg:"-o,--offset" help:"skip this many results"`
+	Limit         int    `arg:"-l,--limit" help:"show at most this many results"`
+	Provider      string `arg:"--provider" help:"aws, azure or gcp, to apply its reserved addresses and limits"`
 }
 
 // IP4SubnetDivide for calls to divide subnet into networks
@@ -65,6 +70,92 @@ type IP4SubnetDivide struct {
 	Bits          int    `arg:"-b,--bits" help:""`
 	SecondaryBits int    `arg:"-s,--secondary-bits" help:""`
 	Pretty        bool   `arg:"-p,--pretty" help:""`
+	Offset        int    `arg:"-o,--offset" help:"skip this many results"`
+	Limit         int    `arg:"-l,--limit" help:"show at most this many results"`
+	Provider      string `arg:"--provider" help:"aws, azure or gcp, to apply its reserved addresses and limits"`
+}
+
+// IP4SubnetVLSM for calls to allocate variable sized subnets by host requirements
+type IP4SubnetVLSM struct {
+	IP           string   `arg:"-i,--ip" help:""`
+	Bits         int      `arg:"-b,--bits" help:""`
+	Requirements []string `arg:"-r,--require" help:"host requirement as name:hosts"`
+	Pretty       bool     `arg:"-p,--pretty" help:""`
+}
+
+// IP4SubnetRelate for calls to show how two subnets relate
+type IP4SubnetRelate struct {
+	Prefixes []string `arg:"positional" help:"two prefixes to compare"`
+	Pretty   bool     `arg:"-p,--pretty" help:""`
+}
+
+// IP4SubnetFree for calls to find free space in a subnet
+type IP4SubnetFree struct {
+	IP        string   `arg:"-i,--ip" help:""`
+	Bits      int      `arg:"-b,--bits" help:""`
+	Allocated []string `arg:"-a,--allocated" help:"allocated prefixes"`
+	Files     []string `arg:"--file" help:"inventory files listing allocated prefixes, - for stdin"`
+	Format    string   `arg:"-f,--format" help:"text, csv or yaml, taken from file extension if not set"`
+	Size      int      `arg:"--size" help:"find a free subnet with this many bits"`
+	BestFit   bool     `arg:"--best-fit" help:"take the subnet from the smallest free block it fits in"`
+	Pretty    bool     `arg:"-p,--pretty" help:""`
+}
+
+// IP4SubnetTree for calls to show a subnet split in halves as a tree
+type IP4SubnetTree struct {
+	IP              string   `arg:"-i,--ip" help:""`
+	Bits            int      `arg:"-b,--bits" help:""`
+	SecondaryBits   int      `arg:"-s,--secondary-bits" help:"split down to subnets with this many bits"`
+	Allocated       []string `arg:"-a,--allocated" help:"allocated prefixes"`
+	Files           []string `arg:"--file" help:"inventory files listing allocated prefixes, - for stdin"`
+	InventoryFormat string   `arg:"--inventory-format" help:"text, csv or yaml, taken from file extension if not set"`
+	Format          string   `arg:"-f,--format" help:"text, dot or mermaid"`
+	Compact         bool     `arg:"-c,--compact" help:"do not split subnets that are all free or all allocated"`
+}
+
+// IP4SubnetReverseZone for calls to write reverse zones for a subnet
+type IP4SubnetReverseZone struct {
+	IP          string   `arg:"-i,--ip" help:""`
+	Bits        int      `arg:"-b,--bits" help:""`
+	Template    string   `arg:"-t,--template" help:"hostname template using {a}, {b}, {c}, {d} for the octets or {ip}"`
+	Files       []string `arg:"--file" help:"inventory files of addresses and names, - for stdin"`
+	Format      string   `arg:"-f,--format" help:"text, csv or yaml, taken from file extension if not set"`
+	NameServers []string `arg:"--ns" help:"name servers for the zone, the first is the primary"`
+	Contact     string   `arg:"--contact" help:"contact mailbox as a name such as hostmaster.example.net"`
+	Serial      uint32   `arg:"--serial" help:"zone serial, YYYYMMDD01 for today if not set"`
+	TTL         int      `arg:"--ttl" help:"default TTL for the zone"`
+	OutputDir   string   `arg:"-o,--output-dir" help:"write each zone to a file in this directory"`
+}
+
+// IP4RandomIPs for calls to get random IPV4 addresses of a type or in a subnet
+type IP4RandomIPs struct {
+	Number  int      `arg:"-n,--number" help:"number of addresses, 10 if not set"`
+	Type    string   `arg:"-t,--type" help:"private, cgnat, public, link-local, multicast or documentation"`
+	IP      string   `arg:"-i,--ip" help:"take addresses from this subnet"`
+	Bits    int      `arg:"-b,--bits" help:""`
+	Unique  bool     `arg:"-u,--unique" help:"do not return any address more than once"`
+	Usable  bool     `arg:"--usable" help:"leave out network and broadcast addresses"`
+	Exclude []string `arg:"-x,--exclude" help:"prefixes or addresses to leave out"`
+}
+
+// IP4SubnetExport for calls to write subnets as device config
+type IP4SubnetExport struct {
+	IP            string   `arg:"-i,--ip" help:""`
+	Bits          int      `arg:"-b,--bits" help:""`
+	SecondaryBits int      `arg:"-s,--secondary-bits" help:"divide into subnets with this many bits"`
+	Files         []string `arg:"--file" help:"inventory files of prefixes and names, - for stdin"`
+	Format        string   `arg:"-f,--format" help:"text, csv or yaml, taken from file extension if not set"`
+	Target        string   `arg:"-t,--target" help:"config to write, see --list"`
+	TemplateFiles []string `arg:"--template-file" help:"text/template files to add as targets named for the file"`
+	List          bool     `arg:"-l,--list" help:"list the targets"`
+	Name          string   `arg:"-n,--name" help:"ACL, prefix list, set or chain name, IPTOOLS if not set"`
+	Action        string   `arg:"-a,--action" help:"permit or deny, permit if not set"`
+	GE            int      `arg:"--ge" help:"prefix list minimum length"`
+	LE            int      `arg:"--le" help:"prefix list maximum length"`
+	Seq           int      `arg:"--seq" help:"first sequence number, 10 if not set"`
+	SeqStep       int      `arg:"--seq-step" help:"gap between sequence numbers, 10 if not set"`
+	NextHop       string   `arg:"--next-hop" help:"next hop for routes"`
+	Interface     string   `arg:"--interface" help:"interface name, numbered by subnet"`
 }
 
 // IP6SubnetGlobalUnicastDescribe for calls to describe a subnet
@@ -90,18 +181,231 @@ type IP6SubnetDescribe struct {
 	YAML   bool   `arg:"-y,--yaml" help:"shwo YAML output"`
 }
 
+// IP6SubnetRanges for calls to get the ranges an IPV6 prefix divides into
+type IP6SubnetRanges struct {
+	IP            string `arg:"-i,--ip" help:"IPV6 prefix"`
+	Bits          int    `arg:"-b,--bits" help:"subnet bits, 64 if not set"`
+	SecondaryBits int    `arg:"-s,--secondary-bits" help:""`
+	Pretty        bool   `arg:"-p,--pretty" help:""`
+	Offset        int    `arg:"-o,--offset" help:"skip this many results"`
+	Limit         int    `arg:"-l,--limit" help:"show at most this many results"`
+}
+
+// IP6SubnetDivide for calls to divide an IPV6 prefix into subnets
+type IP6SubnetDivide struct {
+	IP            string `arg:"-i,--ip" help:"IPV6 prefix"`
+	Bits          int    `arg:"-b,--bits" help:"subnet bits, 64 if not set"`
+	SecondaryBits int    `arg:"-s,--secondary-bits" help:""`
+	Pretty        bool   `arg:"-p,--pretty" help:""`
+	Offset        int    `arg:"-o,--offset" help:"skip this many results"`
+	Limit         int    `arg:"-l,--limit" help:"show at most this many results"`
+}
+
+// IP6ReverseZone for calls to write ip6.arpa zones for a prefix
+type IP6ReverseZone struct {
+	IP          string   `arg:"-i,--ip" help:"IP address"`
+	Bits        int      `arg:"-b,--bits" help:"subnet bits"`
+	Template    string   `arg:"-t,--template" help:"hostname template using {h1} to {h8} for the groups or {ip}"`
+	Files       []string `arg:"--file" help:"inventory files of addresses and names, - for stdin"`
+	Format      string   `arg:"-f,--format" help:"text, csv or yaml, taken from file extension if not set"`
+	NameServers []string `arg:"--ns" help:"name servers for the zone, the first is the primary"`
+	Contact     string   `arg:"--contact" help:"contact mailbox as a name such as hostmaster.example.net"`
+	Serial      uint32   `arg:"--serial" help:"zone serial, YYYYMMDD01 for today if not set"`
+	TTL         int      `arg:"--ttl" help:"default TTL for the zone"`
+	OutputDir   string   `arg:"-o,--output-dir" help:"write each zone to a file in this directory"`
+}
+
 // IP6Subnet IP6 calls
 type IP6Subnet struct {
 	// IP6SubnetGlobalUnicastDescribe *IP6SubnetGlobalUnicastDescribe `arg:"subcommand:global-unicast-describe"`
 	IP6SubnetDescribe *IP6SubnetDescribe `arg:"subcommand:describe"`
 	IP6RandomIPs      *IP6RandomIPs      `arg:"subcommand:random-ips"`
+	IP6ReverseZone    *IP6ReverseZone    `arg:"subcommand:reverse-zone" help:"list or write ip6.arpa zones for a prefix"`
+	IP6SubnetRanges   *IP6SubnetRanges   `arg:"subcommand:ranges" help:"divide a prefix into ranges"`
+	IP6SubnetDivide   *IP6SubnetDivide   `arg:"subcommand:divide" help:"divide a prefix into smaller subnets"`
 }
 
 // IP4Subnet top level IP4 subnet arg
 type IP4Subnet struct {
-	SubnetRanges   *IP4SubnetRanges   `arg:"subcommand:ranges" help:"divide a subnet into ranges"`
-	SubnetDivide   *IP4SubnetDivide   `arg:"subcommand:divide" help:"divide a subnet into smaller subnets"`
-	SubnetDescribe *IP4SubnetDescribe `arg:"subcommand:describe" help:"describe a subnet"`
+	SubnetRanges   *IP4SubnetRanges      `arg:"subcommand:ranges" help:"divide a subnet into ranges"`
+	SubnetDivide   *IP4SubnetDivide      `arg:"subcommand:divide" help:"divide a subnet into smaller subnets"`
+	SubnetDescribe *IP4SubnetDescribe    `arg:"subcommand:describe" help:"describe a subnet"`
+	SubnetVLSM     *IP4SubnetVLSM        `arg:"subcommand:vlsm" help:"allocate variable sized subnets by host requirements"`
+	SubnetRelate   *IP4SubnetRelate      `arg:"subcommand:relate" help:"show how two subnets relate"`
+	SubnetFree     *IP4SubnetFree        `arg:"subcommand:free" help:"find free space in a subnet"`
+	SubnetTree     *IP4SubnetTree        `arg:"subcommand:tree" help:"show a subnet split in halves as a tree"`
+	SubnetReverse  *IP4SubnetReverseZone `arg:"subcommand:reverse-zone" help:"write reverse DNS zones for a subnet"`
+	RandomIPs      *IP4RandomIPs         `arg:"subcommand:random-ips" help:"get random addresses of a type or in a subnet"`
+	Export         *IP4SubnetExport      `arg:"subcommand:export" help:"write subnets as router and firewall config"`
+}
+
+// Summarize for calls to collapse prefixes into the minimal covering set
+type Summarize struct {
+	Prefixes []string `arg:"positional" help:"prefixes to summarize, read from stdin if none are given"`
+	Pretty   bool     `arg:"-p,--pretty" help:""`
+}
+
+// RangeToCIDR for calls to convert address ranges to prefixes
+type RangeToCIDR struct {
+	Ranges []string `arg:"positional" help:"ranges as first-last, read from stdin if none are given"`
+	Pretty bool     `arg:"-p,--pretty" help:""`
+}
+
+// FromArpa for calls to get the addresses and prefixes for reverse DNS names
+type FromArpa struct {
+	Names  []string `arg:"positional" help:"in-addr.arpa or ip6.arpa names, read from stdin if none are given"`
+	Pretty bool     `arg:"-p,--pretty" help:""`
+}
+
+// SetUnion for calls to get the addresses in any of a list of prefixes and ranges
+type SetUnion struct {
+	Values []string `arg:"positional" help:"prefixes, addresses or ranges, read from stdin if none are given"`
+	Ranges bool     `arg:"-r,--ranges" help:"show ranges instead of prefixes"`
+	Pretty bool     `arg:"-p,--pretty" help:""`
+}
+
+// SetIntersect for calls to get the addresses shared by two lists of prefixes and ranges
+type SetIntersect struct {
+	Values []string `arg:"positional" help:"prefixes, addresses or ranges, read from stdin if none are given"`
+	With   []string `arg:"-w,--with,required" help:"prefixes, addresses or ranges to intersect with"`
+	Ranges bool     `arg:"-r,--ranges" help:"show ranges instead of prefixes"`
+	Pretty bool     `arg:"-p,--pretty" help:""`
+}
+
+// SetDiff for calls to remove a list of prefixes and ranges from another
+type SetDiff struct {
+	Values []string `arg:"positional" help:"prefixes, addresses or ranges, read from stdin if none are given"`
+	Minus  []string `arg:"-m,--minus,required" help:"prefixes, addresses or ranges to remove"`
+	Ranges bool     `arg:"-r,--ranges" help:"show ranges instead of prefixes"`
+	Pretty bool     `arg:"-p,--pretty" help:""`
+}
+
+// Set set operations on prefixes and ranges
+type Set struct {
+	Union     *SetUnion     `arg:"subcommand:union" help:"addresses in any of the values"`
+	Intersect *SetIntersect `arg:"subcommand:intersect" help:"addresses in both the values and --with"`
+	Diff      *SetDiff      `arg:"subcommand:diff" help:"addresses in the values but not in --minus"`
+}
+
+// CheckOverlaps for calls to find overlapping prefixes in inventory files
+type CheckOverlaps struct {
+	Files  []string `arg:"positional" help:"inventory files, read from stdin if none are given"`
+	Format string   `arg:"-f,--format" help:"text, csv or yaml, taken from file extension if not set"`
+	JSON   bool     `arg:"-j,--json" help:"JSON output"`
+	YAML   bool     `arg:"-y,--yaml" help:"YAML output"`
+}
+
+// PlanValidate for calls to check an addressing plan
+type PlanValidate struct {
+	File string `arg:"positional" help:"plan file in YAML or JSON, read from stdin if not given"`
+}
+
+// PlanRender for calls to show an addressing plan
+type PlanRender struct {
+	File   string `arg:"positional" help:"plan file in YAML or JSON, read from stdin if not given"`
+	Format string `arg:"-f,--format" help:"table, json, yaml or markdown"`
+}
+
+// Plan calls for addressing plans
+type Plan struct {
+	Validate *PlanValidate `arg:"subcommand:validate" help:"check that networks are aligned, nested and do not overlap"`
+	Render   *PlanRender   `arg:"subcommand:render" help:"show the plan with network details"`
+}
+
+// CloudPlan for calls to split a VPC or VNet into tiers of subnets across zones
+type CloudPlan struct {
+	Provider string   `arg:"--provider,required" help:"aws, azure or gcp"`
+	IP       string   `arg:"-i,--ip" help:"VPC or VNet CIDR"`
+	Bits     int      `arg:"-b,--bits" help:""`
+	Zones    []string `arg:"-z,--zone" help:"availability zone names"`
+	AZs      int      `arg:"-n,--azs" help:"number of availability zones named az1, az2 and so on, 3 if not set"`
+	Tiers    []string `arg:"-t,--tier" help:"tiers as name:weight, public:1 private:2 database:1 if not set"`
+	Pretty   bool     `arg:"-p,--pretty" help:""`
+}
+
+// Cloud calls for cloud provider networks
+type Cloud struct {
+	Plan *CloudPlan `arg:"subcommand:plan" help:"split a VPC or VNet into public, private and database subnets across zones"`
+}
+
+// K8sPlan for calls to size the pod, service and node CIDRs of a Kubernetes cluster
+type K8sPlan struct {
+	ClusterCIDRs []string `arg:"-c,--cluster-cidr" help:"pod CIDRs, one IPV4 and one IPV6 for dual-stack"`
+	NodeMask     int      `arg:"-m,--node-cidr-mask-size" help:"node CIDR mask size for a single-stack cluster"`
+	NodeMaskIPv4 int      `arg:"--node-cidr-mask-size-ipv4" help:"IPV4 node CIDR mask size, 24 if not set"`
+	NodeMaskIPv6 int      `arg:"--node-cidr-mask-size-ipv6" help:"IPV6 node CIDR mask size, 64 if not set"`
+	ServiceCIDRs []string `arg:"-s,--service-cidr" help:"service CIDRs, one IPV4 and one IPV6 for dual-stack"`
+	Nodes        int      `arg:"-n,--nodes" help:"expected number of nodes"`
+	MaxPods      int      `arg:"--max-pods" help:"most pods on a node, 110 if not set"`
+	NodeNetworks []string `arg:"--node-network" help:"networks the nodes are on"`
+	VPCs         []string `arg:"--vpc" help:"VPC or other networks the cluster is in"`
+	Pretty       bool     `arg:"-p,--pretty" help:""`
+}
+
+// K8s calls for Kubernetes cluster networks
+type K8s struct {
+	Plan *K8sPlan `arg:"subcommand:plan" help:"report node, pod and service capacity and overlaps"`
+}
+
+// DHCPConfig for calls to write DHCP server config for a subnet
+type DHCPConfig struct {
+	IP        string        `arg:"-i,--ip" help:"IPV4 or IPV6 subnet"`
+	Bits      int           `arg:"-b,--bits" help:"24 for IPV4 and 64 for IPV6 if not set"`
+	Format    string        `arg:"-f,--format,required" help:"isc, kea or dnsmasq"`
+	Gateway   string        `arg:"-g,--gateway" help:"first, last or none, first if not set"`
+	Exclude   []string      `arg:"-x,--exclude" help:"static addresses, ranges or prefixes to keep out of the pools"`
+	DNS       []string      `arg:"-d,--dns" help:"DNS servers"`
+	LeaseTime time.Duration `arg:"-l,--lease-time" help:"lease time such as 30m or 12h, 1h if not set"`
+}
+
+// IPAMInit for calls to create an IPAM store and add a pool to it
+type IPAMInit struct {
+	Pool   string `arg:"--pool" help:"name of pool to add"`
+	Prefix string `arg:"--prefix" help:"IPV4 or IPV6 prefix for the pool"`
+}
+
+// IPAMAllocate for calls to allocate a subnet or reserve an address in a pool
+type IPAMAllocate struct {
+	Pool    string `arg:"--pool,required" help:"pool to allocate from"`
+	Name    string `arg:"-n,--name,required" help:"name for the allocation or reservation"`
+	Size    int    `arg:"--size" help:"allocate the next free subnet with this many bits"`
+	Prefix  string `arg:"--prefix" help:"allocate this subnet"`
+	IP      string `arg:"-i,--ip" help:"reserve this address"`
+	NextIP  bool   `arg:"--next-ip" help:"reserve the next free address"`
+	Subnet  string `arg:"--subnet" help:"allocation to reserve the next free address in"`
+	BestFit bool   `arg:"--best-fit" help:"take the subnet from the smallest free block it fits in"`
+}
+
+// IPAMRelease for calls to release an allocation or reservation
+type IPAMRelease struct {
+	Pool string `arg:"--pool,required" help:"pool to release from"`
+	Name string `arg:"-n,--name" help:"allocation or reservation to release"`
+	IP   string `arg:"-i,--ip" help:"reserved address to release"`
+}
+
+// IPAMList for calls to list pools, allocations and reservations
+type IPAMList struct {
+	Pool   string `arg:"--pool" help:"only list this pool"`
+	Pretty bool   `arg:"-p,--pretty" help:""`
+	JSON   bool   `arg:"-j,--json" help:"JSON output"`
+	YAML   bool   `arg:"-y,--yaml" help:"YAML output"`
+}
+
+// IPAMShow for calls to show usage and free space for a pool
+type IPAMShow struct {
+	Pool string `arg:"--pool,required" help:"pool to show"`
+	JSON bool   `arg:"-j,--json" help:"JSON output"`
+	YAML bool   `arg:"-y,--yaml" help:"YAML output"`
+}
+
+// IPAM calls for the IPAM store
+type IPAM struct {
+	File     string        `arg:"--file,env:IPTOOLS_IPAM" default:"ipam.json" help:"IPAM store file"`
+	Init     *IPAMInit     `arg:"subcommand:init" help:"create the store and add a pool"`
+	Allocate *IPAMAllocate `arg:"subcommand:allocate" help:"allocate a subnet or reserve an address"`
+	Release  *IPAMRelease  `arg:"subcommand:release" help:"release an allocation or reservation"`
+	List     *IPAMList     `arg:"subcommand:list" help:"list pools, allocations and reservations"`
+	Show     *IPAMShow     `arg:"subcommand:show" help:"show usage and free space for a pool"`
 }
 
 // Utilities utilities
@@ -119,9 +423,19 @@ type UtilsDomainLookup struct {
 
 // Args container for cli pargs
 type Args struct {
-	IP4Subnet *IP4Subnet `arg:"subcommand:subnetip4" help:"Get networks for subnet"`
-	IP6Subnet *IP6Subnet `arg:"subcommand:ip6" help:"Get IP6 address information"`
-	Utilities *Utilities `arg:"subcommand:utilities" help:"Utilities"`
+	IP4Subnet     *IP4Subnet     `arg:"subcommand:subnetip4" help:"Get networks for subnet"`
+	IP6Subnet     *IP6Subnet     `arg:"subcommand:ip6" help:"Get IP6 address information"`
+	Summarize     *Summarize     `arg:"subcommand:summarize" help:"Collapse prefixes into the minimal covering set"`
+	RangeToCIDR   *RangeToCIDR   `arg:"subcommand:range-to-cidr" help:"Convert address ranges to prefixes"`
+	FromArpa      *FromArpa      `arg:"subcommand:from-arpa" help:"Convert reverse DNS names to addresses and prefixes"`
+	Set           *Set           `arg:"subcommand:set" help:"Set operations on prefixes and ranges"`
+	CheckOverlaps *CheckOverlaps `arg:"subcommand:check-overlaps" help:"Find overlapping and duplicate prefixes in inventory files"`
+	Plan          *Plan          `arg:"subcommand:plan" help:"Validate and render addressing plans"`
+	Cloud         *Cloud         `arg:"subcommand:cloud" help:"Plan subnets for cloud provider networks"`
+	K8s           *K8s           `arg:"subcommand:k8s" help:"Plan Kubernetes cluster CIDRs"`
+	DHCPConfig    *DHCPConfig    `arg:"subcommand:dhcp-config" help:"Write ISC dhcpd, Kea or dnsmasq config for a subnet"`
+	IPAM          *IPAM          `arg:"subcommand:ipam" help:"Allocate subnets and addresses from pools kept in a file"`
+	Utilities     *Utilities     `arg:"subcommand:utilities" help:"Utilities"`
 }
 
 // CLIArgs the args structure to be filled at runtime
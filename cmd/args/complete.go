@@ -23,6 +23,48 @@ var ip6Types = []string{
 	"link-local-multicast",
 }
 
+// ip4Types IP4 address types for random addresses
+var ip4Types = []string{
+	"private",
+	"cgnat",
+	"public",
+	"link-local",
+	"multicast",
+	"documentation",
+}
+
+// inventoryFormats formats for files listing prefixes
+var inventoryFormats = []string{"text", "csv", "yaml"}
+
+// treeFormats output formats for subnet trees
+var treeFormats = []string{"text", "dot", "mermaid"}
+
+// planFormats output formats for rendering plans
+var planFormats = []string{"table", "json", "yaml", "markdown"}
+
+// exportTargets device configs subnets can be exported as
+var exportTargets = []string{
+	"cisco-acl",
+	"cisco-interface",
+	"cisco-prefix-list",
+	"cisco-route",
+	"iptables",
+	"junos-interface",
+	"junos-prefix-list",
+	"junos-route",
+	"junos-route-filter",
+	"nftables",
+}
+
+// dhcpFormats DHCP server config formats
+var dhcpFormats = []string{"isc", "kea", "dnsmasq"}
+
+// gatewayPositions where the gateway is in a DHCP scope
+var gatewayPositions = []string{"first", "last", "none"}
+
+// cloudProviders cloud providers with reserved addresses and subnet limits
+var cloudProviders = []string{"aws", "azure", "gcp"}
+
 // Define command structure to enable completion
 var cmd = &complete.Command{
 	Sub: map[string]*complete.Command{
@@ -35,6 +77,9 @@ var cmd = &complete.Command{
 						"bits":           predict.Nothing,
 						"secondary-bits": predict.Nothing,
 						"pretty":         predict.Nothing,
+						"offset":         predict.Nothing,
+						"limit":          predict.Nothing,
+						"provider":       predict.Set(cloudProviders),
 					},
 				},
 				"divide": {
@@ -43,6 +88,9 @@ var cmd = &complete.Command{
 						"bits":           predict.Nothing,
 						"secondary-bits": predict.Nothing,
 						"pretty":         predict.Nothing,
+						"offset":         predict.Nothing,
+						"limit":          predict.Nothing,
+						"provider":       predict.Set(cloudProviders),
 					},
 				},
 				"describe": {
@@ -50,6 +98,89 @@ var cmd = &complete.Command{
 						"ip":             predict.Set(ip4ips),
 						"bits":           predict.Nothing,
 						"secondary-bits": predict.Nothing,
+						"provider":       predict.Set(cloudProviders),
+					},
+				},
+				"vlsm": {
+					Flags: map[string]complete.Predictor{
+						"ip":      predict.Set(ip4ips),
+						"bits":    predict.Nothing,
+						"require": predict.Nothing,
+						"pretty":  predict.Nothing,
+					},
+				},
+				"relate": {
+					Flags: map[string]complete.Predictor{
+						"pretty": predict.Nothing,
+					},
+				},
+				"free": {
+					Flags: map[string]complete.Predictor{
+						"ip":        predict.Set(ip4ips),
+						"bits":      predict.Nothing,
+						"allocated": predict.Nothing,
+						"file":      predict.Files("*"),
+						"format":    predict.Set(inventoryFormats),
+						"size":      predict.Nothing,
+						"best-fit":  predict.Nothing,
+						"pretty":    predict.Nothing,
+					},
+				},
+				"tree": {
+					Flags: map[string]complete.Predictor{
+						"ip":               predict.Set(ip4ips),
+						"bits":             predict.Nothing,
+						"secondary-bits":   predict.Nothing,
+						"allocated":        predict.Nothing,
+						"file":             predict.Files("*"),
+						"inventory-format": predict.Set(inventoryFormats),
+						"format":           predict.Set(treeFormats),
+						"compact":          predict.Nothing,
+					},
+				},
+				"reverse-zone": {
+					Flags: map[string]complete.Predictor{
+						"ip":         predict.Set(ip4ips),
+						"bits":       predict.Nothing,
+						"template":   predict.Nothing,
+						"file":       predict.Files("*"),
+						"format":     predict.Set(inventoryFormats),
+						"ns":         predict.Nothing,
+						"contact":    predict.Nothing,
+						"serial":     predict.Nothing,
+						"ttl":        predict.Nothing,
+						"output-dir": predict.Dirs("*"),
+					},
+				},
+				"random-ips": {
+					Flags: map[string]complete.Predictor{
+						"number":  predict.Nothing,
+						"type":    predict.Set(ip4Types),
+						"ip":      predict.Set(ip4ips),
+						"bits":    predict.Nothing,
+						"unique":  predict.Nothing,
+						"usable":  predict.Nothing,
+						"exclude": predict.Nothing,
+					},
+				},
+				"export": {
+					Flags: map[string]complete.Predictor{
+						"ip":             predict.Set(ip4ips),
+						"bits":           predict.Nothing,
+						"secondary-bits": predict.Nothing,
+						"file":           predict.Files("*"),
+						"format":         predict.Set(inventoryFormats),
+						"target":         predict.Set(exportTargets),
+						"template-file":  predict.Files("*.tmpl"),
+						"list":           predict.Nothing,
+						"name":           predict.Nothing,
+						"action":         predict.Set([]string{"permit", "deny"}),
+						"ge":             predict.Nothing,
+						"le":             predict.Nothing,
+						"seq":            predict.Nothing,
+						"seq-step":       predict.Nothing,
+						"next-hop":       predict.Nothing,
+						"interface":      predict.Nothing,
 					},
 				},
 			},
@@ -73,6 +204,191 @@ var cmd = &complete.Command{
 						"type":   predict.Set(ip6Types),
 					},
 				},
+				"reverse-zone": {
+					Flags: map[string]complete.Predictor{
+						"ip":         predict.Nothing,
+						"bits":       predict.Set(ip6PrefixBits),
+						"template":   predict.Nothing,
+						"file":       predict.Files("*"),
+						"format":     predict.Set(inventoryFormats),
+						"ns":         predict.Nothing,
+						"contact":    predict.Nothing,
+						"serial":     predict.Nothing,
+						"ttl":        predict.Nothing,
+						"output-dir": predict.Dirs("*"),
+					},
+				},
+				"ranges": {
+					Flags: map[string]complete.Predictor{
+						"ip":             predict.Nothing,
+						"bits":           predict.Set(ip6PrefixBits),
+						"secondary-bits": predict.Set(ip6PrefixBits),
+						"pretty":         predict.Nothing,
+						"offset":         predict.Nothing,
+						"limit":          predict.Nothing,
+					},
+				},
+				"divide": {
+					Flags: map[string]complete.Predictor{
+						"ip":             predict.Nothing,
+						"bits":           predict.Set(ip6PrefixBits),
+						"secondary-bits": predict.Set(ip6PrefixBits),
+						"pretty":         predict.Nothing,
+						"offset":         predict.Nothing,
+						"limit":          predict.Nothing,
+					},
+				},
+			},
+		},
+		"summarize": {
+			Flags: map[string]complete.Predictor{
+				"pretty": predict.Nothing,
+			},
+		},
+		"range-to-cidr": {
+			Flags: map[string]complete.Predictor{
+				"pretty": predict.Nothing,
+			},
+		},
+		"from-arpa": {
+			Flags: map[string]complete.Predictor{
+				"pretty": predict.Nothing,
+			},
+		},
+		"set": {
+			Sub: map[string]*complete.Command{
+				"union": {
+					Flags: map[string]complete.Predictor{
+						"ranges": predict.Nothing,
+						"pretty": predict.Nothing,
+					},
+				},
+				"intersect": {
+					Flags: map[string]complete.Predictor{
+						"with":   predict.Nothing,
+						"ranges": predict.Nothing,
+						"pretty": predict.Nothing,
+					},
+				},
+				"diff": {
+					Flags: map[string]complete.Predictor{
+						"minus":  predict.Nothing,
+						"ranges": predict.Nothing,
+						"pretty": predict.Nothing,
+					},
+				},
+			},
+		},
+		"check-overlaps": {
+			Flags: map[string]complete.Predictor{
+				"format": predict.Set(inventoryFormats),
+				"json":   predict.Nothing,
+				"yaml":   predict.Nothing,
+			},
+			Args: predict.Files("*"),
+		},
+		"plan": {
+			Sub: map[string]*complete.Command{
+				"validate": {
+					Args: predict.Files("*"),
+				},
+				"render": {
+					Flags: map[string]complete.Predictor{
+						"format": predict.Set(planFormats),
+					},
+					Args: predict.Files("*"),
+				},
+			},
+		},
+		"cloud": {
+			Sub: map[string]*complete.Command{
+				"plan": {
+					Flags: map[string]complete.Predictor{
+						"provider": predict.Set(cloudProviders),
+						"ip":       predict.Set(ip4ips),
+						"bits":     predict.Nothing,
+						"zone":     predict.Nothing,
+						"azs":      predict.Nothing,
+						"tier":     predict.Nothing,
+						"pretty":   predict.Nothing,
+					},
+				},
+			},
+		},
+		"k8s": {
+			Sub: map[string]*complete.Command{
+				"plan": {
+					Flags: map[string]complete.Predictor{
+						"cluster-cidr":             predict.Nothing,
+						"node-cidr-mask-size":      predict.Nothing,
+						"node-cidr-mask-size-ipv4": predict.Nothing,
+						"node-cidr-mask-size-ipv6": predict.Nothing,
+						"service-cidr":             predict.Nothing,
+						"nodes":                    predict.Nothing,
+						"max-pods":                 predict.Nothing,
+						"node-network":             predict.Nothing,
+						"vpc":                      predict.Nothing,
+						"pretty":                   predict.Nothing,
+					},
+				},
+			},
+		},
+		"dhcp-config": {
+			Flags: map[string]complete.Predictor{
+				"ip":         predict.Set(ip4ips),
+				"bits":       predict.Nothing,
+				"format":     predict.Set(dhcpFormats),
+				"gateway":    predict.Set(gatewayPositions),
+				"exclude":    predict.Nothing,
+				"dns":        predict.Nothing,
+				"lease-time": predict.Nothing,
+			},
+		},
+		"ipam": {
+			Flags: map[string]complete.Predictor{
+				"file": predict.Files("*.json"),
+			},
+			Sub: map[string]*complete.Command{
+				"init": {
+					Flags: map[string]complete.Predictor{
+						"pool":   predict.Nothing,
+						"prefix": predict.Nothing,
+					},
+				},
+				"allocate": {
+					Flags: map[string]complete.Predictor{
+						"pool":     predict.Nothing,
+						"name":     predict.Nothing,
+						"size":     predict.Nothing,
+						"prefix":   predict.Nothing,
+						"ip":       predict.Nothing,
+						"next-ip":  predict.Nothing,
+						"subnet":   predict.Nothing,
+						"best-fit": predict.Nothing,
+					},
+				},
+				"release": {
+					Flags: map[string]complete.Predictor{
+						"pool": predict.Nothing,
+						"name": predict.Nothing,
+						"ip":   predict.Nothing,
+					},
+				},
+				"list": {
+					Flags: map[string]complete.Predictor{
+						"pool":   predict.Nothing,
+						"pretty": predict.Nothing,
+						"json":   predict.Nothing,
+						"yaml":   predict.Nothing,
+					},
+				},
+				"show": {
+					Flags: map[string]complete.Predictor{
+						"pool": predict.Nothing,
+						"json": predict.Nothing,
+						"yaml": predict.Nothing,
+					},
+				},
 			},
 		},
 		"utilities": {
@@ -0,0 +1,145 @@
+package args
+
+import (
+	"reflect"
+	"strings"
+)
+
+// flagKind how a flag takes its values
+type flagKind int
+
+const (
+	// boolFlag a flag that takes no value
+	boolFlag flagKind = iota
+	// valueFlag a flag that takes one value
+	valueFlag
+	// listFlag a flag that takes any number of values
+	listFlag
+)
+
+// command the flags and subcommands of one of the command structs in Args
+type command struct {
+	// names the long name for each name a flag can be given as
+	names map[string]string
+	// kinds how each flag takes its values, by long name
+	kinds       map[string]flagKind
+	subcommands map[string]reflect.Type
+}
+
+// newCommand get the flags and subcommands of a command struct from its arg tags
+func newCommand(t reflect.Type) (c command) {
+	c = command{
+		names:       make(map[string]string),
+		kinds:       make(map[string]flagKind),
+		subcommands: make(map[string]reflect.Type),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		var names []string
+		long := ""
+		flag := true
+		for _, part := range strings.Split(field.Tag.Get("arg"), ",") {
+			switch {
+			case strings.HasPrefix(part, "subcommand:"):
+				c.subcommands[strings.TrimPrefix(part, "subcommand:")] = field.Type.Elem()
+				flag = false
+			case part == "positional" || part == "-":
+				flag = false
+			case strings.HasPrefix(part, "--"):
+				long = part
+				names = append(names, part)
+			case strings.HasPrefix(part, "-"):
+				names = append(names, part)
+			}
+		}
+		if !flag {
+			continue
+		}
+		if long == "" {
+			long = "--" + strings.ToLower(field.Name)
+			names = append(names, long)
+		}
+		for _, name := range names {
+			c.names[name] = long
+		}
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			c.kinds[long] = boolFlag
+		case reflect.Slice:
+			c.kinds[long] = listFlag
+		default:
+			c.kinds[long] = valueFlag
+		}
+	}
+
+	return
+}
+
+// isFlag is an argument a flag, the same test go-arg makes
+func isFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") && strings.TrimLeft(arg, "-") != ""
+}
+
+// MergeRepeatedFlags move the values of a list flag given more than once to its first use
+// go-arg replaces a list each time its flag is given, so --minus a --minus b would keep
+// only b. Giving every value after the first --minus keeps them all. The arguments do
+// not include the program name.
+func MergeRepeatedFlags(arguments []string) (merged []string) {
+	c := newCommand(reflect.TypeOf(Args{}))
+	// groups each flag with its values so later values can be added to the first use
+	groups := [][]string{}
+	first := make(map[string]int)
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if arg == "--" {
+			groups = append(groups, arguments[i:])
+			break
+		}
+		if !isFlag(arg) {
+			if t, ok := c.subcommands[arg]; ok {
+				c = newCommand(t)
+				first = make(map[string]int)
+			}
+			groups = append(groups, []string{arg})
+			continue
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		long, ok := c.names[name]
+		if !ok {
+			groups = append(groups, []string{arg})
+			continue
+		}
+		switch c.kinds[long] {
+		case valueFlag:
+			group := []string{arg}
+			if !hasValue && i+1 < len(arguments) {
+				i++
+				group = append(group, arguments[i])
+			}
+			groups = append(groups, group)
+		case listFlag:
+			values := []string{}
+			if hasValue {
+				values = append(values, value)
+			} else {
+				for i+1 < len(arguments) && !isFlag(arguments[i+1]) && arguments[i+1] != "--" {
+					i++
+					values = append(values, arguments[i])
+				}
+			}
+			if j, ok := first[long]; ok {
+				groups[j] = append(groups[j], values...)
+				continue
+			}
+			first[long] = len(groups)
+			groups = append(groups, append([]string{name}, values...))
+		default:
+			groups = append(groups, []string{arg})
+		}
+	}
+	for _, group := range groups {
+		merged = append(merged, group...)
+	}
+
+	return
+}
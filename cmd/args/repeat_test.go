@@ -0,0 +1,38 @@
+package args
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMergeRepeatedFlags(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		arguments string
+		merged    string
+	}{
+		{"set diff 10.0.0.0/8 --minus 10.1.0.0/16 --minus 10.2.0.0/15",
+			"set diff 10.0.0.0/8 --minus 10.1.0.0/16 10.2.0.0/15"},
+		// short and long names are the same flag and a value after = is the only one
+		{"set diff 10.0.0.0/8 -m 10.1.0.0/16 -p --minus=10.2.0.0/15 10.4.0.0/14",
+			"set diff 10.0.0.0/8 -m 10.1.0.0/16 10.2.0.0/15 -p 10.4.0.0/14"},
+		// flags with one value are left where they are
+		{"subnetip4 vlsm -i 10.0.0.0/22 -r a:500 -b 22 -r b:60 c:2",
+			"subnetip4 vlsm -i 10.0.0.0/22 -r a:500 b:60 c:2 -b 22"},
+		{"k8s plan -c 10.244.0.0/16 -s 10.96.0.0/12 -c fd00:10:244::/56 -s fd00:10:96::/108",
+			"k8s plan -c 10.244.0.0/16 fd00:10:244::/56 -s 10.96.0.0/12 fd00:10:96::/108"},
+		// -a is a list for free and a value for export
+		{"subnetip4 export -i 10.0.0.0/24 -a deny -t cisco-acl -a permit",
+			"subnetip4 export -i 10.0.0.0/24 -a deny -t cisco-acl -a permit"},
+		{"subnetip4 free --file - --file inventory.txt -- -x",
+			"subnetip4 free --file - inventory.txt -- -x"},
+	}
+	for _, test := range tests {
+		merged := MergeRepeatedFlags(strings.Fields(test.arguments))
+		t.Log(merged)
+		is.Equal(strings.Join(merged, " "), test.merged)
+	}
+}
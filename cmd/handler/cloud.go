@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/cloud"
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// cloudProvider get a cloud provider by name, nil if there is no name
+func cloudProvider(name string) *cloud.Provider {
+	if name == "" {
+		return nil
+	}
+	provider, err := cloud.ProviderByName(name)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	return provider
+}
+
+// checkProvider check a subnet and its secondary subnet against a provider's limits
+// With a secondary subnet the subnet is the VPC or VNet it is split from.
+func checkProvider(provider *cloud.Provider, s, s2 *ipv4subnet.Subnet) {
+	if provider == nil {
+		return
+	}
+	if s != s2 {
+		err := provider.CheckNetwork(s.Prefix())
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+	err := provider.CheckSubnet(s2.Prefix())
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+// providerRows get table rows for a provider's reserved addresses and usable hosts in a subnet
+func providerRows(provider *cloud.Provider, prefix netip.Prefix) (rows [][]*simpletable.Cell) {
+	usable, err := provider.UsableRange(prefix)
+	if err != nil {
+		exitWithError(err)
+	}
+	rows = append(rows, row("Cloud Provider", provider.Title))
+	for _, reserved := range provider.ReservedAddrs(prefix) {
+		rows = append(rows, row("Reserved Address", fmt.Sprintf("%s %s", reserved.Addr, reserved.Purpose)))
+	}
+	rows = append(rows, row("Usable Range", usable.String()))
+	rows = append(rows, row("Usable Hosts", printer.Sprintf("%d", provider.UsableHosts(prefix))))
+
+	return
+}
+
+// providerRange get the usable range of a network for a provider
+func providerRange(provider *cloud.Provider, r ipv4subnet.Range, bits int) ipv4subnet.Range {
+	usable, err := provider.UsableRange(netip.PrefixFrom(r.First(), bits))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	return usable
+}
+
+// CloudPlan split a VPC or VNet into a subnet for each tier in each availability zone
+func CloudPlan(providerName, ip string, bits int, zones []string, azs int, tierStrs []string, pretty bool) {
+	provider := cloudProvider(providerName)
+	if ip == "" {
+		fmt.Println("No VPC or VNet CIDR supplied")
+		os.Exit(1)
+	}
+	// Default to 16 bits
+	if bits == 0 {
+		bits = 16
+	}
+	prefix := parsePrefix(ip, bits)
+
+	if len(zones) == 0 {
+		if azs == 0 {
+			azs = 3
+		}
+		zones = cloud.ZoneNames(azs)
+	}
+	tiers := []cloud.Tier{}
+	for _, tierStr := range tierStrs {
+		tier, err := cloud.ParseTier(tierStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		tiers = append(tiers, tier)
+	}
+
+	plan, err := cloud.NewPlan(provider, prefix, zones, tiers)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if !pretty {
+		for _, subnet := range plan.Subnets {
+			fmt.Printf("%s %s %s %d\n", subnet.Tier, subnet.Zone, subnet.Prefix, subnet.UsableHosts)
+		}
+		for _, spare := range plan.Spare {
+			fmt.Printf("spare %s\n", spare)
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Tier"},
+			{Align: simpletable.AlignCenter, Text: "Zone"},
+			{Align: simpletable.AlignCenter, Text: "Subnet"},
+			{Align: simpletable.AlignCenter, Text: "Usable Range"},
+			{Align: simpletable.AlignCenter, Text: "Usable Hosts"},
+		},
+	}
+	var total int64
+	for _, subnet := range plan.Subnets {
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: subnet.Tier},
+			{Align: simpletable.AlignLeft, Text: subnet.Zone},
+			{Align: simpletable.AlignLeft, Text: subnet.Prefix.String()},
+			{Align: simpletable.AlignLeft, Text: subnet.Usable.String()},
+			{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", subnet.UsableHosts)},
+		})
+		total += subnet.UsableHosts
+	}
+	for _, spare := range plan.Spare {
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: "spare"},
+			{Align: simpletable.AlignLeft, Text: ""},
+			{Align: simpletable.AlignLeft, Text: spare.String()},
+			{Align: simpletable.AlignLeft, Text: ""},
+			{Align: simpletable.AlignRight, Text: ""},
+		})
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: fmt.Sprintf("%s %s", provider.Title, provider.NetworkName)},
+			{},
+			{Align: simpletable.AlignLeft, Text: plan.Network.String()},
+			{},
+			{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", total)},
+		},
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/imarsman/iptools/pkg/dhcp"
+	"github.com/imarsman/iptools/pkg/ipset"
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+)
+
+// DHCPConfig write ISC dhcpd, Kea or dnsmasq config for an IPV4 or IPV6 subnet
+func DHCPConfig(ip string, bits int, format, gateway string, exclude, dns []string, leaseTime time.Duration) {
+	if ip == "" {
+		fmt.Println("No subnet supplied")
+		os.Exit(1)
+	}
+	// Default to 24 bits for IPV4 and 64 for IPV6
+	if bits == 0 {
+		bits = defaultBits(ip)
+	}
+	prefix := parsePrefix(ip, bits)
+
+	options := dhcp.Options{Gateway: gateway, LeaseTime: leaseTime}
+	excluded, err := ipset.Parse(exclude...)
+	if err != nil {
+		exitWithError(err)
+	}
+	options.Exclude = excluded.Ranges()
+	for _, value := range dns {
+		var addr netip.Addr
+		addr, err = ipv4util.ParseAddr(value)
+		if err != nil {
+			exitWithError(err)
+		}
+		options.DNS = append(options.DNS, addr)
+	}
+
+	scope, err := dhcp.NewScope(prefix, options)
+	if err != nil {
+		exitWithError(err)
+	}
+	err = dhcp.Write(os.Stdout, format, scope)
+	if err != nil {
+		exitWithError(err)
+	}
+}
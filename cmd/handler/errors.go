@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// Exit codes for errors so scripts can tell failures apart
+const (
+	// ExitError any error without a more specific code
+	ExitError = 1
+	// ExitInvalidPrefix an address, prefix or range could not be parsed
+	ExitInvalidPrefix = 2
+	// ExitSplitToFewerBits a subnet was split by fewer bits than its own
+	ExitSplitToFewerBits = 3
+	// ExitAddressOverflow a calculation went past the end of the address space
+	ExitAddressOverflow = 4
+	// ExitWrongFamily an IPV6 value was given where IPV4 is needed or the reverse
+	ExitWrongFamily = 5
+)
+
+// exitCode get the exit code for an error
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, util.ErrInvalidPrefix):
+		return ExitInvalidPrefix
+	case errors.Is(err, util.ErrSplitToFewerBits):
+		return ExitSplitToFewerBits
+	case errors.Is(err, util.ErrAddressOverflow):
+		return ExitAddressOverflow
+	case errors.Is(err, util.ErrWrongFamily):
+		return ExitWrongFamily
+	default:
+		return ExitError
+	}
+}
+
+// exitWithError print an error and exit with the code for it
+func exitWithError(err error) {
+	fmt.Println(err)
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/export"
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// IP4SubnetExport write a subnet, its division or the prefixes in inventory files as device config
+// A subnet is divided into the subnets inside it, not the networks of that size in its
+// class block that divide lists. Template files are added as targets named for the
+// file and with a single template file and no target it is used.
+func IP4SubnetExport(ip string, bits, secondaryBits int, files []string, format, target string, templateFiles []string, list bool, options export.Options) {
+	for _, file := range templateFiles {
+		t, err := export.RegisterFile(file)
+		if err != nil {
+			exitWithError(err)
+		}
+		if target == "" && len(templateFiles) == 1 {
+			target = t.Name
+		}
+	}
+
+	if list {
+		table := simpletable.New()
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Target"},
+				{Align: simpletable.AlignCenter, Text: "Description"},
+			},
+		}
+		list, err := export.Targets()
+		if err != nil {
+			exitWithError(err)
+		}
+		for _, t := range list {
+			table.Body.Cells = append(table.Body.Cells, row(t.Name, t.Description))
+		}
+		table.SetStyle(simpletable.StyleCompactLite)
+		fmt.Println(table.String())
+		return
+	}
+	if target == "" {
+		fmt.Println("Specify a target, see --list")
+		os.Exit(1)
+	}
+	if ip != "" && len(files) > 0 {
+		fmt.Println("Specify either a subnet or inventory files")
+		os.Exit(1)
+	}
+
+	defaults := export.DefaultOptions()
+	if options.Name == "" {
+		options.Name = defaults.Name
+	}
+	if options.Action == "" {
+		options.Action = defaults.Action
+	}
+	if options.Seq == 0 {
+		options.Seq = defaults.Seq
+	}
+	if options.SeqStep == 0 {
+		options.SeqStep = defaults.SeqStep
+	}
+
+	var prefixes []ipv4subnet.NamedPrefix
+	if ip == "" {
+		if secondaryBits != 0 {
+			fmt.Println("Secondary bits divide a subnet given with --ip")
+			os.Exit(1)
+		}
+		prefixes = readInventoryFiles(files, format)
+	} else {
+		// Default to 24 bits
+		if bits == 0 {
+			bits = 24
+		}
+		prefix := parsePrefix(ip, bits)
+		if secondaryBits == 0 {
+			prefixes = append(prefixes, ipv4subnet.NamedPrefix{Prefix: prefix})
+		} else {
+			var err error
+			prefixes, err = export.Divide(prefix, secondaryBits)
+			if err != nil {
+				exitWithError(err)
+			}
+		}
+	}
+
+	err := export.Export(os.Stdout, target, prefixes, options)
+	if err != nil {
+		exitWithError(err)
+	}
+}
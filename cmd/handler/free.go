@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// IP4SubnetFree list the free space in a subnet or find a free subnet of a size
+// Allocations come from args and from inventory files.
+func IP4SubnetFree(ip string, bits int, allocatedStrs, files []string, format string, size int, bestFit, pretty bool) {
+	// Default to 24 bits
+	if bits == 0 {
+		bits = 24
+	}
+
+	prefix := parsePrefix(ip, bits)
+
+	s, err := ipv4subnet.NewFromPrefix(prefix.String())
+	if err != nil {
+		exitWithError(err)
+	}
+
+	allocated := []netip.Prefix{}
+	for _, allocatedStr := range allocatedStrs {
+		p, err := parseAddrOrPrefix(allocatedStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		allocated = append(allocated, p)
+	}
+	if len(files) > 0 {
+		for _, namedPrefix := range readInventoryFiles(files, format) {
+			allocated = append(allocated, namedPrefix.Prefix)
+		}
+	}
+
+	if size != 0 {
+		var subnet *ipv4subnet.Subnet
+		if bestFit {
+			subnet, err = s.BestFitFree(allocated, size)
+		} else {
+			subnet, err = s.FirstFree(allocated, size)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+		if !pretty {
+			fmt.Println(subnet.CIDR())
+			return
+		}
+		table := simpletable.New()
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Category"},
+				{Align: simpletable.AlignCenter, Text: "Value"},
+			},
+		}
+		table.Body.Cells = append(table.Body.Cells, row("Subnet", subnet.CIDR()))
+		table.Body.Cells = append(table.Body.Cells, row("First IP", subnet.IP()))
+		table.Body.Cells = append(table.Body.Cells, row("Last IP", subnet.Last()))
+		table.Body.Cells = append(table.Body.Cells, row("Hosts", printer.Sprintf("%d", subnet.Hosts())))
+		table.Body.Cells = append(table.Body.Cells, row("Usable Hosts", printer.Sprintf("%d", subnet.UsableHosts())))
+		table.SetStyle(simpletable.StyleCompactLite)
+		fmt.Println(table.String())
+		return
+	}
+
+	free, err := s.FreeSubnets(allocated)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if !pretty {
+		for _, f := range free {
+			fmt.Println(f.CIDR())
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Free"},
+			{Align: simpletable.AlignCenter, Text: "First IP"},
+			{Align: simpletable.AlignCenter, Text: "Last IP"},
+			{Align: simpletable.AlignCenter, Text: "Addresses"},
+		},
+	}
+	var total int64
+	for _, f := range free {
+		total += f.Hosts()
+		cells := []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: f.CIDR()},
+			{Align: simpletable.AlignLeft, Text: f.IP().String()},
+			{Align: simpletable.AlignLeft, Text: f.Last().String()},
+			{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", f.Hosts())},
+		}
+		table.Body.Cells = append(table.Body.Cells, cells)
+	}
+	table.Footer = &simpletable.Footer{
+		Cells: []*simpletable.Cell{
+			{},
+			{},
+			{Align: simpletable.AlignRight, Text: "Total"},
+			{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", total)},
+		},
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
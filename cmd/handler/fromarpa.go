@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/dnszone"
+)
+
+// FromArpa convert in-addr.arpa and ip6.arpa names to the addresses or prefixes they stand for
+// Full names give an address and partial names a prefix.
+func FromArpa(names []string, pretty bool) {
+	names = inputValues(names)
+	if len(names) == 0 {
+		fmt.Println("No names supplied")
+		os.Exit(1)
+	}
+	prefixes := []netip.Prefix{}
+	for _, name := range names {
+		prefix, err := dnszone.FromArpa(name)
+		if err != nil {
+			exitWithError(err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	if !pretty {
+		for _, prefix := range prefixes {
+			fmt.Println(arpaValue(prefix))
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Name"},
+			{Align: simpletable.AlignCenter, Text: "Address or Prefix"},
+		},
+	}
+	for i, prefix := range prefixes {
+		table.Body.Cells = append(table.Body.Cells, row(names[i], arpaValue(prefix)))
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
+
+// arpaValue get the address for a full length prefix or else the prefix
+func arpaValue(prefix netip.Prefix) string {
+	if prefix.Bits() == prefix.Addr().BitLen() {
+		return prefix.Addr().String()
+	}
+
+	return prefix.String()
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"math"
 	"net/netip"
 	"os"
@@ -21,19 +23,104 @@ import (
 	"github.com/imarsman/iptools/pkg/util"
 )
 
+// parsePrefix parse a prefix in any notation ipv4util.ParsePrefix understands
+// An address given without a mask gets bits as its prefix length.
 func parsePrefix(ip string, bits int) netip.Prefix {
-	// Try for prefix with bits (alternative is IP)
-	prefix, err := netip.ParsePrefix(ip)
+	if _, mask := ipv4util.SplitPrefix(ip); mask == "" {
+		ip = fmt.Sprintf("%s/%d", strings.TrimSpace(ip), bits)
+	}
+	prefix, err := ipv4util.ParsePrefix(ip)
 	if err != nil {
-		prefixStr := fmt.Sprintf("%s/%d", ip, bits)
-		prefix, err = netip.ParsePrefix(prefixStr)
+		exitWithError(err)
+	}
+
+	return prefix
+}
+
+// parseAddrOrPrefix parse a prefix or a single address as a full length prefix
+func parseAddrOrPrefix(value string) (prefix netip.Prefix, err error) {
+	return ipv4util.ParsePrefix(value)
+}
+
+// unmapPrefix get an IPV4 mapped IPV6 prefix as IPV4 so it takes the IPV4 path
+// Secondary bits counted against the mapped prefix are made IPV4 bits as well.
+func unmapPrefix(prefix netip.Prefix, secondaryBits int) (netip.Prefix, int) {
+	unmapped := util.UnmapPrefix(prefix)
+	if unmapped != prefix && secondaryBits > 32 {
+		secondaryBits -= 96
+	}
+
+	return unmapped, secondaryBits
+}
+
+// inputValues get values from args or if there are none from stdin
+// Values on stdin can be separated by whitespace or commas.
+func inputValues(values []string) (list []string) {
+	if len(values) != 0 {
+		return values
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.FieldsFunc(scanner.Text(), func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		list = append(list, fields...)
+	}
+	if err := scanner.Err(); err != nil {
+		exitWithError(err)
+	}
+
+	return
+}
+
+// readInventoryFiles read named prefixes from inventory files or from stdin if there are none
+// The format for each file comes from its extension unless format is set.
+func readInventoryFiles(files []string, format string) (prefixes []ipv4subnet.NamedPrefix) {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, file := range files {
+		fileFormat := format
+		if fileFormat == "" {
+			fileFormat = ipv4subnet.InventoryFormat(file)
+		}
+		reader := os.Stdin
+		if file != "-" {
+			var err error
+			reader, err = os.Open(file)
+			if err != nil {
+				exitWithError(err)
+			}
+		}
+		list, err := ipv4subnet.ReadInventory(reader, fileFormat)
+		reader.Close()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(fmt.Errorf("%s: %w", file, err))
 		}
+		prefixes = append(prefixes, list...)
 	}
 
-	return prefix
+	return
+}
+
+// page iterate over at most limit values after skipping offset values
+// A limit of 0 means there is no limit.
+func page[T any](seq iter.Seq[T], offset, limit int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		for value := range seq {
+			i++
+			if i <= offset {
+				continue
+			}
+			if !yield(value) {
+				return
+			}
+			if limit > 0 && i-offset >= limit {
+				return
+			}
+		}
+	}
 }
 
 var printer = message.NewPrinter(language.English)
@@ -117,15 +204,13 @@ func LookupDomain(domains []string, mxLookup bool, toJSON, toYAML bool) {
 	if toJSON {
 		bytes, err := json.MarshalIndent(&ipsForDomains, "", "  ")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 		fmt.Println(string(bytes))
 	} else if toYAML {
 		bytes, err := yaml.Marshal(&ipsForDomains)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 		fmt.Println(string(bytes))
 	} else {
@@ -133,36 +218,54 @@ func LookupDomain(domains []string, mxLookup bool, toJSON, toYAML bool) {
 	}
 }
 
+// ip4TypeName get the special-purpose registry name for an IPV4 subnet, or
+// Multicast or Public for subnets not in the registry
+func ip4TypeName(prefix netip.Prefix) string {
+	if entry, ok := util.IP4SpecialPurposePrefix(prefix); ok {
+		return entry.Name
+	}
+	if prefix.Addr().IsMulticast() {
+		return "Multicast"
+	}
+
+	return "Public"
+}
+
 // IP4SubnetDescribe describe a subnet
 // Needs review and cleanup
 // Investigate iptools subnetip4 describe -ip 10.32.0.0 -bits 23 -secondary-bits
 // 24
 // Consider working with a prefix and not an ip string
-func IP4SubnetDescribe(ip string, bits int, secondaryBits int) {
-	// Default to 24 bits
+func IP4SubnetDescribe(ip string, bits int, secondaryBits int, providerName string) {
+	// Default to 24 bits, 64 for IPV6
 	if bits == 0 {
-		bits = 24
+		bits = defaultBits(ip)
 	}
 
-	prefix := parsePrefix(ip, bits)
+	prefix, secondaryBits := unmapPrefix(parsePrefix(ip, bits), secondaryBits)
+	if !prefix.Addr().Is4() {
+		subnetDescribe(prefix, secondaryBits, providerName)
+		return
+	}
 
 	var s *ipv4subnet.Subnet
 
 	s, err := ipv4subnet.NewFromPrefix(prefix.String())
 	if err != nil {
-		os.Exit(1)
+		exitWithError(err)
 	}
 	var s2 *ipv4subnet.Subnet
 	if secondaryBits != 0 {
 		prefixStr := fmt.Sprintf("%s/%d", prefix.Addr().String(), secondaryBits)
 		s2, err = ipv4subnet.NewFromPrefix(prefixStr)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	} else {
 		s2 = s
 	}
+	provider := cloudProvider(providerName)
+	checkProvider(provider, s, s2)
 
 	table := simpletable.New()
 
@@ -187,11 +290,16 @@ func IP4SubnetDescribe(ip string, bits int, secondaryBits int) {
 	}
 	table.Body.Cells = append(table.Body.Cells, row("IP Class", class))
 
-	ipType := "Public"
-	if s.IP().IsPrivate() {
-		ipType = "Private"
+	table.Body.Cells = append(table.Body.Cells, row("IP Type", ip4TypeName(s.Prefix())))
+	if entry, ok := util.IP4SpecialPurposePrefix(s.Prefix()); ok {
+		table.Body.Cells = append(table.Body.Cells, row("Special Purpose Block", entry.Prefix))
+		table.Body.Cells = append(table.Body.Cells, row("Special Purpose RFC", entry.RFC))
+		table.Body.Cells = append(table.Body.Cells, row("Source", entry.Source))
+		table.Body.Cells = append(table.Body.Cells, row("Destination", entry.Destination))
+		table.Body.Cells = append(table.Body.Cells, row("Forwardable", entry.Forwardable))
+		table.Body.Cells = append(table.Body.Cells, row("Globally Reachable", entry.GloballyReachable))
+		table.Body.Cells = append(table.Body.Cells, row("Reserved by Protocol", entry.ReservedByProtocol))
 	}
-	table.Body.Cells = append(table.Body.Cells, row("IP Type", ipType))
 	table.Body.Cells = append(table.Body.Cells, row("Binary Subnet Mask", s.BinaryMask()))
 	table.Body.Cells = append(table.Body.Cells, row("Binary ID", s.BinaryID()))
 
@@ -223,18 +331,26 @@ func IP4SubnetDescribe(ip string, bits int, secondaryBits int) {
 		table.Body.Cells = append(table.Body.Cells, row("Network Hosts", printer.Sprintf("%d", s.Hosts())))
 		table.Body.Cells = append(table.Body.Cells, row("Secondary Network Hosts", printer.Sprintf("%d", s2.Hosts())))
 	}
+	if provider != nil {
+		table.Body.Cells = append(table.Body.Cells, providerRows(provider, s2.Prefix())...)
+	}
 
 	table.SetStyle(simpletable.StyleCompactLite)
 	fmt.Println(table.String())
 }
 
 // IP4SubnetRanges divide a subnet into ranges
-func IP4SubnetRanges(ip string, bits int, secondaryBits int) {
-	// Default to 24 bits
+// Offset and limit page through the ranges, a limit of 0 meaning all.
+func IP4SubnetRanges(ip string, bits int, secondaryBits int, offset, limit int, providerName string) {
+	// Default to 24 bits, 64 for IPV6
 	if bits == 0 {
-		bits = 24
+		bits = defaultBits(ip)
+	}
+	prefix, secondaryBits := unmapPrefix(parsePrefix(ip, bits), secondaryBits)
+	if !prefix.Addr().Is4() {
+		subnetRanges(prefix, secondaryBits, offset, limit, providerName, args.CLIArgs.IP4Subnet.SubnetRanges.Pretty)
+		return
 	}
-	prefix := parsePrefix(ip, bits)
 
 	var s *ipv4subnet.Subnet
 
@@ -242,30 +358,37 @@ func IP4SubnetRanges(ip string, bits int, secondaryBits int) {
 
 	s, err := ipv4subnet.NewFromPrefix(prefix.String())
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		exitWithError(err)
 	}
 
-	ranges := []ipv4subnet.Range{}
+	var ranges iter.Seq[ipv4subnet.Range]
 	var s2 *ipv4subnet.Subnet
 	if secondaryBits != 0 {
-		prefix := fmt.Sprintf("%s/%d", ip, secondaryBits)
-		s2, err = ipv4subnet.NewFromPrefix(prefix)
+		secondaryPrefix := fmt.Sprintf("%s/%d", prefix.Addr(), secondaryBits)
+		s2, err = ipv4subnet.NewFromPrefix(secondaryPrefix)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
-		ranges, err = s.SecondaryIPRanges(s2)
+		ranges, err = s.SecondaryIPRangesSeq(s2)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	} else {
 		s2 = s
-		ranges, err = s.IPRanges()
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+		ranges = s.IPRangesSeq()
+	}
+	provider := cloudProvider(providerName)
+	checkProvider(provider, s, s2)
+	ranges = page(ranges, offset, limit)
+	if provider != nil {
+		// only the addresses between those the provider reserves
+		networkRanges := ranges
+		ranges = func(yield func(ipv4subnet.Range) bool) {
+			for r := range networkRanges {
+				if !yield(providerRange(provider, r, s2.Prefix().Bits())) {
+					return
+				}
+			}
 		}
 	}
 	if args.CLIArgs.IP4Subnet.SubnetRanges.Pretty {
@@ -277,11 +400,7 @@ func IP4SubnetRanges(ip string, bits int, secondaryBits int) {
 				{Align: simpletable.AlignCenter, Text: "Value"},
 			},
 		}
-		ipType := "Public"
-		if s.IP().IsPrivate() {
-			ipType = "Private"
-		}
-		table.Body.Cells = append(table.Body.Cells, row("IP Type", ipType))
+		table.Body.Cells = append(table.Body.Cells, row("IP Type", ip4TypeName(s.Prefix())))
 		table.Body.Cells = append(table.Body.Cells, row("Subnet", s.CIDR()))
 		table.Body.Cells = append(table.Body.Cells, row("Subnet IP", s.IP().String()))
 
@@ -313,10 +432,14 @@ func IP4SubnetRanges(ip string, bits int, secondaryBits int) {
 		} else {
 			table.Body.Cells = append(table.Body.Cells, row("Networks", s.Networks()))
 			table.Body.Cells = append(table.Body.Cells, row("Secondary Networks", s2.Networks()))
-			table.Body.Cells = append(table.Body.Cells, row("Effective Networks", len(ranges)))
+			table.Body.Cells = append(table.Body.Cells, row("Effective Networks", s.EffectiveNetworks(s2)))
 			table.Body.Cells = append(table.Body.Cells, row("Network Hosts", printer.Sprintf("%d", s.Hosts())))
 			table.Body.Cells = append(table.Body.Cells, row("Secondary Network Hosts", printer.Sprintf("%d", s2.Hosts())))
 		}
+		if provider != nil {
+			table.Body.Cells = append(table.Body.Cells, row("Cloud Provider", provider.Title))
+			table.Body.Cells = append(table.Body.Cells, row("Usable Hosts", printer.Sprintf("%d", provider.UsableHosts(s2.Prefix()))))
+		}
 		fmt.Println()
 		table.SetStyle(simpletable.StyleCompactLite)
 		fmt.Println(table.String())
@@ -330,56 +453,59 @@ func IP4SubnetRanges(ip string, bits int, secondaryBits int) {
 				{Align: simpletable.AlignCenter, Text: "End"},
 			},
 		}
-		for _, r := range ranges {
+		for r := range ranges {
 			table.Body.Cells = append(table.Body.Cells, row(r.First().String(), r.Last().String()))
 		}
 		table.SetStyle(simpletable.StyleCompactLite)
 		fmt.Println(table.String())
 	} else {
-		for _, r := range ranges {
-			fmt.Println(r.String())
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		for r := range ranges {
+			fmt.Fprintln(out, r.String())
 		}
 	}
 }
 
 // IP4SubnetDivide divide a subnet into ranges
-func IP4SubnetDivide(ip string, bits int, secondaryBits int) {
-	// Default to 24 bits
+// Offset and limit page through the subnets, a limit of 0 meaning all.
+func IP4SubnetDivide(ip string, bits int, secondaryBits int, offset, limit int, providerName string) {
+	// Default to 24 bits, 64 for IPV6
 	if bits == 0 {
-		bits = 24
+		bits = defaultBits(ip)
 	}
 
-	prefix := parsePrefix(ip, bits)
+	prefix, secondaryBits := unmapPrefix(parsePrefix(ip, bits), secondaryBits)
+	if !prefix.Addr().Is4() {
+		subnetDivide(prefix, secondaryBits, offset, limit, providerName, args.CLIArgs.IP4Subnet.SubnetDivide.Pretty)
+		return
+	}
 
 	var s *ipv4subnet.Subnet
 	s, err := ipv4subnet.NewFromPrefix(prefix.String())
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		exitWithError(err)
 	}
 
-	subnets := []*ipv4subnet.Subnet{}
+	var subnets iter.Seq[*ipv4subnet.Subnet]
 	var s2 = s
 	if secondaryBits != 0 {
-		prefix := fmt.Sprintf("%s/%d", ip, secondaryBits)
-		s2, err = ipv4subnet.NewFromPrefix(prefix)
+		secondaryPrefix := fmt.Sprintf("%s/%d", prefix.Addr(), secondaryBits)
+		s2, err = ipv4subnet.NewFromPrefix(secondaryPrefix)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
-		subnets, err = s.SecondarySubnets(s2)
+		subnets, err = s.SecondarySubnetsSeq(s2)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	} else {
 		s2 = s
-		subnets, err = s.Subnets()
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
+		subnets = s.SubnetsSeq()
 	}
+	provider := cloudProvider(providerName)
+	checkProvider(provider, s, s2)
+	subnets = page(subnets, offset, limit)
 	if args.CLIArgs.IP4Subnet.SubnetDivide.Pretty {
 		table := simpletable.New()
 
@@ -389,11 +515,7 @@ func IP4SubnetDivide(ip string, bits int, secondaryBits int) {
 				{Align: simpletable.AlignCenter, Text: "Value"},
 			},
 		}
-		ipType := "Public"
-		if s.IP().IsPrivate() {
-			ipType = "Private"
-		}
-		table.Body.Cells = append(table.Body.Cells, row("IP Type", ipType))
+		table.Body.Cells = append(table.Body.Cells, row("IP Type", ip4TypeName(s.Prefix())))
 		table.Body.Cells = append(table.Body.Cells, row("Subnet", s.Prefix().String()))
 		table.Body.Cells = append(table.Body.Cells, row("Subnet IP", s.IP().String()))
 
@@ -428,6 +550,10 @@ func IP4SubnetDivide(ip string, bits int, secondaryBits int) {
 			table.Body.Cells = append(table.Body.Cells, row("Network Hosts", printer.Sprintf("%d", s.Hosts())))
 			table.Body.Cells = append(table.Body.Cells, row("Secondary Network Hosts", printer.Sprintf("%d", s2.Hosts())))
 		}
+		if provider != nil {
+			table.Body.Cells = append(table.Body.Cells, row("Cloud Provider", provider.Title))
+			table.Body.Cells = append(table.Body.Cells, row("Usable Hosts", printer.Sprintf("%d", provider.UsableHosts(s2.Prefix()))))
+		}
 		fmt.Println()
 		table.SetStyle(simpletable.StyleCompactLite)
 		fmt.Println(table.String())
@@ -442,18 +568,36 @@ func IP4SubnetDivide(ip string, bits int, secondaryBits int) {
 				{Align: simpletable.AlignCenter, Text: "Subnets"},
 			},
 		}
-		for _, s := range subnets {
+		if provider != nil {
+			table.Header.Cells = append(table.Header.Cells,
+				&simpletable.Cell{Align: simpletable.AlignCenter, Text: "Usable Range"},
+				&simpletable.Cell{Align: simpletable.AlignCenter, Text: "Usable Hosts"},
+			)
+		}
+		for s := range subnets {
 			cell := []*simpletable.Cell{
 				{Align: simpletable.AlignLeft, Text: fmt.Sprintf("%s", s.String())},
 			}
+			if provider != nil {
+				usable, err := provider.UsableRange(s.Prefix())
+				if err != nil {
+					exitWithError(err)
+				}
+				cell = append(cell,
+					&simpletable.Cell{Align: simpletable.AlignLeft, Text: usable.String()},
+					&simpletable.Cell{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", provider.UsableHosts(s.Prefix()))},
+				)
+			}
 			table.Body.Cells = append(table.Body.Cells, cell)
 		}
 		table.SetStyle(simpletable.StyleCompactLite)
 
 		fmt.Println(table.String())
 	} else {
-		for _, s := range subnets {
-			fmt.Println(s.String())
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		for s := range subnets {
+			fmt.Fprintln(out, s.String())
 		}
 	}
 }
@@ -483,38 +627,32 @@ func IP6SubnetDescribe(ip string, bits int, random bool, ip6Type string, json, y
 		if ip6Type == ipv6.GlobalUnicastName {
 			addr, err = ipv6.RandAddrGlobalUnicast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 		} else if ip6Type == ipv6.LinkLocalName {
 			addr, err = ipv6.RandAddrLinkLocal()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 		} else if ip6Type == ipv6.PrivateName {
 			addr, err = ipv6.RandAddrPrivate()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 		} else if ip6Type == ipv6.MulticastName {
 			addr, err = ipv6.RandAddrMulticast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 		} else if ip6Type == ipv6.LinkLocalMulticastName {
 			addr, err = ipv6.RandAddrLinkLocalMulticast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 		} else if ip6Type == ipv6.InterfaceLocalMulticastName {
 			addr, err = ipv6.RandAddrInterfaceLocalMulticast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 		} else {
 			fmt.Println("No valid type specified")
@@ -531,6 +669,27 @@ func IP6SubnetDescribe(ip string, bits int, random bool, ip6Type string, json, y
 	}
 }
 
+// ip6RegistryRows add the address space block and any special-purpose registry entry for an address
+func ip6RegistryRows(addr netip.Addr, table *simpletable.Table, ipSummary *ipv6.IPSummary) {
+	if block, ok := util.IP6AddressSpace(addr); ok {
+		ipSummary.AddressSpace = block.Name
+		table.Body.Cells = append(table.Body.Cells, row("Address Space", block.Name))
+	}
+	entry, ok := util.IP6SpecialPurpose(addr)
+	if !ok {
+		return
+	}
+	ipSummary.SpecialPurpose = &entry
+	table.Body.Cells = append(table.Body.Cells, row("Special Purpose", entry.Name))
+	table.Body.Cells = append(table.Body.Cells, row("Special Purpose Block", entry.Prefix))
+	table.Body.Cells = append(table.Body.Cells, row("Special Purpose RFC", entry.RFC))
+	table.Body.Cells = append(table.Body.Cells, row("Source", entry.Source))
+	table.Body.Cells = append(table.Body.Cells, row("Destination", entry.Destination))
+	table.Body.Cells = append(table.Body.Cells, row("Forwardable", entry.Forwardable))
+	table.Body.Cells = append(table.Body.Cells, row("Globally Reachable", entry.GloballyReachable))
+	table.Body.Cells = append(table.Body.Cells, row("Reserved by Protocol", entry.ReservedByProtocol))
+}
+
 // ip6SubnetDisplay describe a link local IP
 func ip6SubnetDisplay(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML bool) {
 	var ipSummary = ipv6.IPSummary{}
@@ -551,12 +710,13 @@ func ip6SubnetDisplay(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML bool)
 	table.Body.Cells = append(table.Body.Cells, row("IP Type", util.AddrTypeName(addr)))
 	ipSummary.TypePrefix = ipv6.AddrTypePrefix(addr).Masked().String()
 	table.Body.Cells = append(table.Body.Cells, row("Type Prefix", ipv6.AddrTypePrefix(addr).Masked()))
+	ip6RegistryRows(addr, table, &ipSummary)
 	ipSummary.IP = addr.String()
 	table.Body.Cells = append(table.Body.Cells, row("IP", addr.String()))
-	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.LinkLocalUnicast, ipv6.UniqueLocal, ipv6.Private) {
+	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.LinkLocalUnicast, ipv6.UniqueLocal) {
 		solicitedNodeAddr, err := ipv6.AddrSolicitedNodeMulticast(addr)
 		if err != nil {
-			panic(err)
+			exitWithError(err)
 		}
 		ipSummary.SolicitedNodeMulticast = solicitedNodeAddr.String()
 		table.Body.Cells = append(table.Body.Cells,
@@ -579,13 +739,13 @@ func ip6SubnetDisplay(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML bool)
 	}
 	ipSummary.SubnetID = ipv6.AddrSubnet(addr)
 	table.Body.Cells = append(table.Body.Cells, row("Subnet ID", fmt.Sprintf("%s", ipv6.AddrSubnet(addr))))
-	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.Private, ipv6.LinkLocalUnicast) {
+	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.UniqueLocal, ipv6.LinkLocalUnicast) {
 		number := printer.Sprintf("%.0f", math.Exp2(16))
 		ipSummary.Subnets = int64(math.Exp2(16))
 		table.Body.Cells = append(table.Body.Cells, row("Subnets", number))
 	}
 	// Handle global id for appropriate types
-	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.Private) {
+	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.UniqueLocal) {
 		value, err = ipv6.AddrGlobalID(addr)
 		if err != nil {
 			fmt.Println(err)
@@ -595,7 +755,7 @@ func ip6SubnetDisplay(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML bool)
 	}
 	ipSummary.InterfaceID = ipv6.Interface(addr)
 	table.Body.Cells = append(table.Body.Cells, row("Interface ID", fmt.Sprintf("%s", ipv6.Interface(addr))))
-	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.Private, ipv6.LinkLocalUnicast) {
+	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast, ipv6.UniqueLocal, ipv6.LinkLocalUnicast) {
 		number := printer.Sprintf("%.0f", math.Exp2(64))
 		ipSummary.Addresses = int64(math.Exp2(64))
 		table.Body.Cells = append(table.Body.Cells, row("Addresses", number))
@@ -608,10 +768,8 @@ func ip6SubnetDisplay(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML bool)
 		ipSummary.Link = ipv6.AddrLink(addr)
 		table.Body.Cells = append(table.Body.Cells, row("Link", ipv6.AddrLink(addr)))
 	}
-	if ipv6.HasType(util.AddrType(addr), ipv6.GlobalUnicast) {
-		ipSummary.IPV6Arpa = ipv6.Arpa(addr)
-		table.Body.Cells = append(table.Body.Cells, row("ip6.arpa", fmt.Sprintf("%s", ipv6.Arpa(addr))))
-	}
+	ipSummary.IPV6Arpa = ipv6.Arpa(addr)
+	table.Body.Cells = append(table.Body.Cells, row("ip6.arpa", fmt.Sprintf("%s", ipv6.Arpa(addr))))
 	ipSummary.SubnetFirstAddress = ipv6.First(addr).StringExpanded()
 	table.Body.Cells = append(table.Body.Cells, row("Subnet first address", ipv6.First(addr).StringExpanded()))
 	ipSummary.SubnetLastAddress = ipv6.Last(addr).StringExpanded()
@@ -624,15 +782,13 @@ func ip6SubnetDisplay(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML bool)
 	if toJSON {
 		bytes, err := json.MarshalIndent(&ipSummary, "", "  ")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 		fmt.Println(string(bytes))
 	} else if toYAML {
 		bytes, err := yaml.Marshal(&ipSummary)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 		fmt.Println(string(bytes))
 	} else {
@@ -661,6 +817,7 @@ func ip6SubnetDisplayBasic(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML
 	table.Body.Cells = append(table.Body.Cells, row("IP Type", util.AddrTypeName(addr)))
 	ipSummary.TypePrefix = ipv6.AddrTypePrefix(addr).Masked().String()
 	table.Body.Cells = append(table.Body.Cells, row("Type Prefix", ipv6.AddrTypePrefix(addr).Masked()))
+	ip6RegistryRows(addr, table, &ipSummary)
 	ipSummary.IP = addr.String()
 	table.Body.Cells = append(table.Body.Cells, row("IP", addr.String()))
 	if (prefix != netip.Prefix{}) {
@@ -692,15 +849,13 @@ func ip6SubnetDisplayBasic(addr netip.Addr, prefix netip.Prefix, toJSON, toYAML
 	if toJSON {
 		bytes, err := json.MarshalIndent(&ipSummary, "", "  ")
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 		fmt.Println(string(bytes))
 	} else if toYAML {
 		bytes, err := yaml.Marshal(&ipSummary)
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 		fmt.Println(string(bytes))
 	} else {
@@ -722,8 +877,7 @@ func IP6RandomIPs(ip6Type string, number int) {
 		for i := 0; i < number; i++ {
 			addr, err = ipv6.RandAddrGlobalUnicast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 			fmt.Println(addr.StringExpanded())
 		}
@@ -731,8 +885,7 @@ func IP6RandomIPs(ip6Type string, number int) {
 		for i := 0; i < number; i++ {
 			addr, err = ipv6.RandAddrLinkLocal()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 			fmt.Println(addr.StringExpanded())
 		}
@@ -740,8 +893,7 @@ func IP6RandomIPs(ip6Type string, number int) {
 		for i := 0; i < number; i++ {
 			addr, err = ipv6.RandAddrPrivate()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 			fmt.Println(addr.StringExpanded())
 		}
@@ -749,8 +901,7 @@ func IP6RandomIPs(ip6Type string, number int) {
 		for i := 0; i < number; i++ {
 			addr, err = ipv6.RandAddrMulticast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 			fmt.Println(addr.StringExpanded())
 		}
@@ -758,8 +909,7 @@ func IP6RandomIPs(ip6Type string, number int) {
 		for i := 0; i < number; i++ {
 			addr, err = ipv6.RandAddrInterfaceLocalMulticast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 			fmt.Println(addr.StringExpanded())
 		}
@@ -767,8 +917,7 @@ func IP6RandomIPs(ip6Type string, number int) {
 		for i := 0; i < number; i++ {
 			addr, err = ipv6.RandAddrLinkLocalMulticast()
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				exitWithError(err)
 			}
 			fmt.Println(addr.StringExpanded())
 		}
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+	"gopkg.in/yaml.v3"
+
+	"github.com/imarsman/iptools/pkg/ipam"
+)
+
+// poolInfo summary of an IPAM pool for display
+type poolInfo struct {
+	Name         string   `json:"name" yaml:"name"`
+	Prefix       string   `json:"prefix" yaml:"prefix"`
+	Addresses    string   `json:"addresses" yaml:"addresses"`
+	Used         string   `json:"used" yaml:"used"`
+	Utilization  float64  `json:"utilization" yaml:"utilization"`
+	Allocations  int      `json:"allocations" yaml:"allocations"`
+	Reservations int      `json:"reservations" yaml:"reservations"`
+	Free         []string `json:"free" yaml:"free"`
+}
+
+// newPoolInfo get the summary for a pool
+func newPoolInfo(pool *ipam.Pool) (info poolInfo, err error) {
+	free, err := pool.Free()
+	if err != nil {
+		return
+	}
+	size, used := pool.Size(), pool.Used()
+	utilization, _ := new(big.Rat).SetFrac(new(big.Int).Mul(used, big.NewInt(100)), size).Float64()
+
+	info = poolInfo{
+		Name:         pool.Name,
+		Prefix:       pool.Prefix.String(),
+		Addresses:    size.String(),
+		Used:         used.String(),
+		Utilization:  utilization,
+		Allocations:  len(pool.Allocations),
+		Reservations: len(pool.Reservations),
+		Free:         []string{},
+	}
+	for _, f := range free {
+		info.Free = append(info.Free, f.String())
+	}
+
+	return
+}
+
+// IPAMInit create the IPAM store if needed and add a pool to it
+func IPAMInit(file, poolName, prefixStr string) {
+	err := ipam.Init(file)
+	if err != nil {
+		exitWithError(err)
+	}
+	if poolName == "" && prefixStr == "" {
+		fmt.Printf("store %s\n", file)
+		return
+	}
+
+	prefix, err := parseAddrOrPrefix(prefixStr)
+	if err != nil {
+		exitWithError(err)
+	}
+	err = ipam.Update(file, func(store *ipam.Store) (err error) {
+		pool, err := store.AddPool(poolName, prefix)
+		if err != nil {
+			return
+		}
+		fmt.Printf("%s %s\n", pool.Name, pool.Prefix)
+
+		return
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+// IPAMAllocate allocate a subnet or reserve an address in a pool
+func IPAMAllocate(file, poolName, name string, size int, prefixStr, ip string, nextIP bool, subnet string, bestFit bool) {
+	count := 0
+	for _, set := range []bool{size != 0, prefixStr != "", ip != "", nextIP} {
+		if set {
+			count++
+		}
+	}
+	if count != 1 {
+		fmt.Println("One of size, prefix, ip or next-ip must be supplied")
+		os.Exit(1)
+	}
+
+	var prefix netip.Prefix
+	var addr netip.Addr
+	var err error
+	if prefixStr != "" {
+		prefix, err = parseAddrOrPrefix(prefixStr)
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+	if ip != "" {
+		prefix, err = parseAddrOrPrefix(ip)
+		if err != nil {
+			exitWithError(err)
+		}
+		addr = prefix.Addr()
+	}
+
+	err = ipam.Update(file, func(store *ipam.Store) (err error) {
+		pool, err := store.Pool(poolName)
+		if err != nil {
+			return
+		}
+		switch {
+		case size != 0:
+			var allocation *ipam.Allocation
+			allocation, err = pool.Allocate(name, size, bestFit)
+			if err != nil {
+				return
+			}
+			fmt.Printf("%s %s\n", allocation.Name, allocation.Prefix)
+		case prefixStr != "":
+			var allocation *ipam.Allocation
+			allocation, err = pool.AllocatePrefix(name, prefix)
+			if err != nil {
+				return
+			}
+			fmt.Printf("%s %s\n", allocation.Name, allocation.Prefix)
+		default:
+			var reservation *ipam.Reservation
+			if nextIP {
+				reservation, err = pool.ReserveNext(name, subnet)
+			} else {
+				reservation, err = pool.Reserve(name, addr)
+			}
+			if err != nil {
+				return
+			}
+			fmt.Printf("%s %s\n", reservation.Name, reservation.Addr)
+		}
+
+		return
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+// IPAMRelease release an allocation or reservation by name or a reservation by address
+func IPAMRelease(file, poolName, name, ip string) {
+	if (name == "") == (ip == "") {
+		fmt.Println("One of name or ip must be supplied")
+		os.Exit(1)
+	}
+
+	err := ipam.Update(file, func(store *ipam.Store) (err error) {
+		pool, err := store.Pool(poolName)
+		if err != nil {
+			return
+		}
+		if name != "" {
+			return pool.Release(name)
+		}
+		var prefix netip.Prefix
+		prefix, err = parseAddrOrPrefix(ip)
+		if err != nil {
+			return
+		}
+
+		return pool.ReleaseAddr(prefix.Addr())
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+// IPAMList list pools with their allocations and reservations
+func IPAMList(file, poolName string, pretty, toJSON, toYAML bool) {
+	err := ipam.View(file, func(store *ipam.Store) (err error) {
+		if poolName != "" {
+			var pool *ipam.Pool
+			pool, err = store.Pool(poolName)
+			if err != nil {
+				return
+			}
+			store = &ipam.Store{Pools: []*ipam.Pool{pool}}
+		}
+
+		if toJSON {
+			var bytes []byte
+			bytes, err = json.MarshalIndent(store, "", "  ")
+			if err != nil {
+				return
+			}
+			fmt.Println(string(bytes))
+			return
+		} else if toYAML {
+			var bytes []byte
+			bytes, err = yaml.Marshal(store)
+			if err != nil {
+				return
+			}
+			fmt.Println(string(bytes))
+			return
+		}
+
+		table := simpletable.New()
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Pool"},
+				{Align: simpletable.AlignCenter, Text: "Kind"},
+				{Align: simpletable.AlignCenter, Text: "Name"},
+				{Align: simpletable.AlignCenter, Text: "Value"},
+				{Align: simpletable.AlignCenter, Text: "Subnet"},
+			},
+		}
+		add := func(values ...string) {
+			if !pretty {
+				fmt.Println(values[0], values[1], values[2], values[3], values[4])
+				return
+			}
+			cells := []*simpletable.Cell{}
+			for _, value := range values {
+				cells = append(cells, &simpletable.Cell{Align: simpletable.AlignLeft, Text: value})
+			}
+			table.Body.Cells = append(table.Body.Cells, cells)
+		}
+		for _, pool := range store.Pools {
+			add(pool.Name, "pool", pool.Name, pool.Prefix.String(), "-")
+			for _, a := range pool.Allocations {
+				add(pool.Name, "allocation", a.Name, a.Prefix.String(), "-")
+			}
+			for _, r := range pool.Reservations {
+				subnet := r.Subnet
+				if subnet == "" {
+					subnet = "-"
+				}
+				add(pool.Name, "reservation", r.Name, r.Addr.String(), subnet)
+			}
+		}
+		if pretty {
+			table.SetStyle(simpletable.StyleCompactLite)
+			fmt.Println(table.String())
+		}
+
+		return
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+}
+
+// IPAMShow show usage and free space for a pool
+func IPAMShow(file, poolName string, toJSON, toYAML bool) {
+	var info poolInfo
+	err := ipam.View(file, func(store *ipam.Store) (err error) {
+		pool, err := store.Pool(poolName)
+		if err != nil {
+			return
+		}
+		info, err = newPoolInfo(pool)
+
+		return
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if toJSON {
+		bytes, err := json.MarshalIndent(&info, "", "  ")
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(bytes))
+		return
+	} else if toYAML {
+		bytes, err := yaml.Marshal(&info)
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(bytes))
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Category"},
+			{Align: simpletable.AlignCenter, Text: "Value"},
+		},
+	}
+	table.Body.Cells = append(table.Body.Cells, row("Pool", info.Name))
+	table.Body.Cells = append(table.Body.Cells, row("Prefix", info.Prefix))
+	table.Body.Cells = append(table.Body.Cells, row("Addresses", info.Addresses))
+	table.Body.Cells = append(table.Body.Cells, row("Used", info.Used))
+	table.Body.Cells = append(table.Body.Cells, row("Utilization", fmt.Sprintf("%.2f%%", info.Utilization)))
+	table.Body.Cells = append(table.Body.Cells, row("Allocations", info.Allocations))
+	table.Body.Cells = append(table.Body.Cells, row("Reservations", info.Reservations))
+	for _, free := range info.Free {
+		table.Body.Cells = append(table.Body.Cells, row("Free", free))
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/k8s"
+)
+
+// parsePrefixes parse a list of prefixes, exiting on the first that is invalid
+func parsePrefixes(values []string) (prefixes []netip.Prefix) {
+	for _, value := range values {
+		prefix, err := parseAddrOrPrefix(value)
+		if err != nil {
+			exitWithError(err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return
+}
+
+// K8sPlan report the node, pod and service capacity of a Kubernetes cluster
+// As with kube-controller-manager the node CIDR mask size is for single-stack clusters
+// and dual-stack clusters set a mask size for each family.
+func K8sPlan(clusterCIDRs []string, nodeMask, nodeMaskIPv4, nodeMaskIPv6 int, serviceCIDRs []string, nodes, maxPods int,
+	nodeNetworks, vpcNetworks []string, pretty bool) {
+	if len(clusterCIDRs) == 0 {
+		fmt.Println("No cluster CIDR supplied")
+		os.Exit(1)
+	}
+	config := k8s.Config{
+		ClusterCIDRs: parsePrefixes(clusterCIDRs),
+		ServiceCIDRs: parsePrefixes(serviceCIDRs),
+		NodeMaskIPv4: nodeMaskIPv4,
+		NodeMaskIPv6: nodeMaskIPv6,
+		Nodes:        nodes,
+		MaxPods:      maxPods,
+		NodeNetworks: parsePrefixes(nodeNetworks),
+		VPCNetworks:  parsePrefixes(vpcNetworks),
+	}
+	if nodeMask != 0 {
+		if len(config.ClusterCIDRs) > 1 {
+			fmt.Println("Use --node-cidr-mask-size-ipv4 and --node-cidr-mask-size-ipv6 for a dual-stack cluster")
+			os.Exit(1)
+		}
+		if config.ClusterCIDRs[0].Addr().Unmap().Is4() {
+			config.NodeMaskIPv4 = nodeMask
+		} else {
+			config.NodeMaskIPv6 = nodeMask
+		}
+	}
+
+	plan, err := k8s.NewPlan(config)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if !pretty {
+		for _, pods := range plan.Pods {
+			fmt.Printf("cluster %s node-mask %d nodes %d pods-per-node %d\n",
+				pods.CIDR, pods.NodeMask, pods.MaxNodes, pods.PodsPerNode)
+		}
+		for _, service := range plan.Services {
+			line := fmt.Sprintf("service %s ips %s", service.CIDR, service.IPs)
+			if service.APIServerIP.IsValid() {
+				line += fmt.Sprintf(" api-server %s", service.APIServerIP)
+			}
+			if service.DNSIP.IsValid() {
+				line += fmt.Sprintf(" dns %s", service.DNSIP)
+			}
+			fmt.Println(line)
+		}
+		fmt.Printf("max-nodes %d max-pods %d\n", plan.MaxNodes, plan.MaxPods)
+		for _, overlap := range plan.Overlaps {
+			fmt.Printf("overlap %s %s %s %s\n",
+				overlap.First.Name, overlap.First.Prefix, overlap.Second.Name, overlap.Second.Prefix)
+		}
+	} else {
+		table := simpletable.New()
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Category"},
+				{Align: simpletable.AlignCenter, Text: "Value"},
+			},
+		}
+		for _, pods := range plan.Pods {
+			table.Body.Cells = append(table.Body.Cells, row("Cluster CIDR", pods.CIDR))
+			table.Body.Cells = append(table.Body.Cells, row("Node CIDR Mask", fmt.Sprintf("/%d", pods.NodeMask)))
+			table.Body.Cells = append(table.Body.Cells, row("Node CIDRs", printer.Sprintf("%d", pods.MaxNodes)))
+			table.Body.Cells = append(table.Body.Cells, row("Addresses Per Node", bigCount(pods.NodeAddrs)))
+			table.Body.Cells = append(table.Body.Cells, row("Pods Per Node", printer.Sprintf("%d", pods.PodsPerNode)))
+		}
+		for _, service := range plan.Services {
+			table.Body.Cells = append(table.Body.Cells, row("Service CIDR", service.CIDR))
+			table.Body.Cells = append(table.Body.Cells, row("Service IPs", bigCount(service.IPs)))
+			if service.APIServerIP.IsValid() {
+				table.Body.Cells = append(table.Body.Cells, row("API Server IP", service.APIServerIP))
+			}
+			if service.DNSIP.IsValid() {
+				table.Body.Cells = append(table.Body.Cells, row("Cluster DNS IP", service.DNSIP))
+			}
+		}
+		if plan.Nodes != 0 {
+			table.Body.Cells = append(table.Body.Cells, row("Expected Nodes", printer.Sprintf("%d", plan.Nodes)))
+		}
+		table.Body.Cells = append(table.Body.Cells, row("Max Pods Per Node", printer.Sprintf("%d", plan.Config.MaxPods)))
+		table.Body.Cells = append(table.Body.Cells, row("Max Nodes", printer.Sprintf("%d", plan.MaxNodes)))
+		table.Body.Cells = append(table.Body.Cells, row("Max Pods", printer.Sprintf("%d", plan.MaxPods)))
+		for _, overlap := range plan.Overlaps {
+			table.Body.Cells = append(table.Body.Cells, row("Overlap", fmt.Sprintf("%s %s and %s %s",
+				overlap.First.Name, overlap.First.Prefix, overlap.Second.Name, overlap.Second.Prefix)))
+		}
+		table.SetStyle(simpletable.StyleCompactLite)
+		fmt.Println(table.String())
+	}
+
+	for _, warning := range plan.Warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+	if len(plan.Overlaps) > 0 {
+		exitWithError(fmt.Errorf("%d overlaps found", len(plan.Overlaps)))
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alexeyco/simpletable"
+	"gopkg.in/yaml.v3"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// overlapInfo a pair of overlapping prefixes for output
+type overlapInfo struct {
+	First      string `yaml:"first" json:"first"`
+	FirstName  string `yaml:"firstname,omitempty" json:"firstname,omitempty"`
+	Second     string `yaml:"second" json:"second"`
+	SecondName string `yaml:"secondname,omitempty" json:"secondname,omitempty"`
+	Relation   string `yaml:"relation" json:"relation"`
+	Shared     string `yaml:"shared" json:"shared"`
+}
+
+// overlapInfoSet the result of an overlap check
+type overlapInfoSet struct {
+	Prefixes int           `yaml:"prefixes" json:"prefixes"`
+	Overlaps []overlapInfo `yaml:"overlaps" json:"overlaps"`
+}
+
+// CheckOverlaps report every pair of prefixes in inventory files that overlap
+func CheckOverlaps(files []string, format string, toJSON, toYAML bool) {
+	prefixes := readInventoryFiles(files, format)
+	overlaps := ipv4subnet.Overlaps(prefixes)
+
+	infoSet := overlapInfoSet{Prefixes: len(prefixes), Overlaps: []overlapInfo{}}
+	for _, o := range overlaps {
+		infoSet.Overlaps = append(infoSet.Overlaps, overlapInfo{
+			First:      o.First.Prefix.String(),
+			FirstName:  o.First.Name,
+			Second:     o.Second.Prefix.String(),
+			SecondName: o.Second.Name,
+			Relation:   o.Relation,
+			Shared:     o.Shared.String(),
+		})
+	}
+
+	if toJSON {
+		bytes, err := json.MarshalIndent(&infoSet, "", "  ")
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(bytes))
+		return
+	} else if toYAML {
+		bytes, err := yaml.Marshal(&infoSet)
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(bytes))
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Prefix"},
+			{Align: simpletable.AlignCenter, Text: "Name"},
+			{Align: simpletable.AlignCenter, Text: "Relation"},
+			{Align: simpletable.AlignCenter, Text: "Prefix"},
+			{Align: simpletable.AlignCenter, Text: "Name"},
+			{Align: simpletable.AlignCenter, Text: "Shared Range"},
+		},
+	}
+	for _, o := range infoSet.Overlaps {
+		cells := []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: o.First},
+			{Align: simpletable.AlignLeft, Text: o.FirstName},
+			{Align: simpletable.AlignLeft, Text: o.Relation},
+			{Align: simpletable.AlignLeft, Text: o.Second},
+			{Align: simpletable.AlignLeft, Text: o.SecondName},
+			{Align: simpletable.AlignLeft, Text: o.Shared},
+		}
+		table.Body.Cells = append(table.Body.Cells, cells)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+
+	fmt.Printf("%d prefixes checked, %d overlaps found\n", infoSet.Prefixes, len(infoSet.Overlaps))
+	if len(infoSet.Overlaps) > 0 {
+		fmt.Println()
+		fmt.Println(table.String())
+	}
+}
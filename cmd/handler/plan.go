@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+	"gopkg.in/yaml.v3"
+
+	"github.com/imarsman/iptools/pkg/plan"
+)
+
+const (
+	// planTable table for the terminal
+	planTable = "table"
+	// planJSON nested JSON
+	planJSON = "json"
+	// planYAML nested YAML
+	planYAML = "yaml"
+	// planMarkdown Markdown table
+	planMarkdown = "markdown"
+)
+
+// renderedNetwork a plan network with its describe fields
+type renderedNetwork struct {
+	Name         string `json:"name" yaml:"name"`
+	Kind         string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	VLAN         int    `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+	Prefix       string `json:"prefix" yaml:"prefix"`
+	Purpose      string `json:"purpose,omitempty" yaml:"purpose,omitempty"`
+	plan.Details `yaml:",inline"`
+	Networks     []*renderedNetwork `json:"networks,omitempty" yaml:"networks,omitempty"`
+}
+
+// renderedPlan a plan with describe fields for every network
+type renderedPlan struct {
+	Name     string             `json:"name,omitempty" yaml:"name,omitempty"`
+	Networks []*renderedNetwork `json:"networks" yaml:"networks"`
+}
+
+// readPlan read a plan from a file or from stdin if the file is empty or -
+func readPlan(file string) (p *plan.Plan) {
+	var reader io.ReadCloser = os.Stdin
+	if file != "" && file != "-" {
+		var err error
+		reader, err = os.Open(file)
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+	p, err := plan.Read(reader)
+	reader.Close()
+	if err != nil {
+		exitWithError(fmt.Errorf("%s: %w", file, err))
+	}
+
+	return
+}
+
+// renderNetworks add describe fields to a list of networks and the networks inside them
+func renderNetworks(networks []*plan.Network, parentPath string) (rendered []*renderedNetwork) {
+	for _, network := range networks {
+		path := network.Name
+		if parentPath != "" {
+			path = parentPath + "/" + path
+		}
+		details, err := network.Details()
+		if err != nil {
+			exitWithError(fmt.Errorf("%s: %w", path, err))
+		}
+		rendered = append(rendered, &renderedNetwork{
+			Name:     network.Name,
+			Kind:     network.Kind,
+			VLAN:     network.VLAN,
+			Prefix:   network.Prefix,
+			Purpose:  network.Purpose,
+			Details:  details,
+			Networks: renderNetworks(network.Networks, path),
+		})
+	}
+
+	return
+}
+
+// PlanValidate check a plan and list any problems
+func PlanValidate(file string) {
+	p := readPlan(file)
+
+	issues := p.Validate()
+	for _, issue := range issues {
+		fmt.Println(issue.String())
+	}
+	if len(issues) > 0 {
+		exitWithError(fmt.Errorf("%d problems found", len(issues)))
+	}
+
+	count := 0
+	p.Walk(func(*plan.Network, string, int) {
+		count++
+	})
+	fmt.Printf("%d networks ok\n", count)
+}
+
+// PlanRender show a plan with the describe fields for each network
+func PlanRender(file, format string) {
+	p := readPlan(file)
+	rendered := renderedPlan{Name: p.Name, Networks: renderNetworks(p.Networks, "")}
+
+	switch format {
+	case planJSON:
+		bytes, err := json.MarshalIndent(&rendered, "", "  ")
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(bytes))
+	case planYAML:
+		bytes, err := yaml.Marshal(&rendered)
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(string(bytes))
+	case planMarkdown:
+		writeMarkdownPlan(rendered)
+	case planTable, "":
+		writeTablePlan(rendered)
+	default:
+		fmt.Printf("Unknown format %s, expected table, json, yaml or markdown\n", format)
+		os.Exit(1)
+	}
+}
+
+// planRows get a row of values for each network in order
+// Names are indented by depth with dots, as tables trim spaces, unless paths is set,
+// when the full path is used.
+func planRows(networks []*renderedNetwork, parentPath string, depth int, paths bool) (rows [][]string) {
+	for _, network := range networks {
+		path := network.Name
+		if parentPath != "" {
+			path = parentPath + "/" + path
+		}
+		name := strings.Repeat("· ", depth) + network.Name
+		if paths {
+			name = path
+		}
+		vlan := ""
+		if network.VLAN != 0 {
+			vlan = fmt.Sprintf("%d", network.VLAN)
+		}
+		broadcast := network.Broadcast
+		if broadcast == "" {
+			broadcast = "-"
+		}
+		rows = append(rows, []string{
+			name, network.Kind, vlan, network.Prefix, network.Network, broadcast, network.Mask, network.UsableHosts, network.Purpose,
+		})
+		rows = append(rows, planRows(network.Networks, path, depth+1, paths)...)
+	}
+
+	return
+}
+
+// planHeadings column headings for plan rows
+var planHeadings = []string{"Name", "Kind", "VLAN", "Prefix", "Network", "Broadcast", "Mask", "Usable Hosts", "Purpose"}
+
+// writeTablePlan write a plan as a table
+func writeTablePlan(rendered renderedPlan) {
+	table := simpletable.New()
+	table.Header = &simpletable.Header{}
+	for _, heading := range planHeadings {
+		table.Header.Cells = append(table.Header.Cells, &simpletable.Cell{Align: simpletable.AlignCenter, Text: heading})
+	}
+	for _, values := range planRows(rendered.Networks, "", 0, false) {
+		cells := []*simpletable.Cell{}
+		for i, value := range values {
+			align := simpletable.AlignLeft
+			if planHeadings[i] == "Usable Hosts" || planHeadings[i] == "VLAN" {
+				align = simpletable.AlignRight
+			}
+			cells = append(cells, &simpletable.Cell{Align: align, Text: value})
+		}
+		table.Body.Cells = append(table.Body.Cells, cells)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	if rendered.Name != "" {
+		fmt.Println(rendered.Name)
+		fmt.Println()
+	}
+	fmt.Println(table.String())
+}
+
+// writeMarkdownPlan write a plan as a Markdown table
+func writeMarkdownPlan(rendered renderedPlan) {
+	if rendered.Name != "" {
+		fmt.Printf("# %s\n\n", rendered.Name)
+	}
+	fmt.Printf("| %s |\n", strings.Join(planHeadings, " | "))
+	fmt.Printf("|%s\n", strings.Repeat(" --- |", len(planHeadings)))
+	for _, values := range planRows(rendered.Networks, "", 0, true) {
+		for i, value := range values {
+			values[i] = strings.ReplaceAll(value, "|", `\|`)
+		}
+		fmt.Printf("| %s |\n", strings.Join(values, " | "))
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// IP4RandomIPs print random IPV4 addresses of a type or from a subnet
+func IP4RandomIPs(addrType, ip string, bits, number int, unique, usable bool, excludeStrs []string) {
+	if number == 0 {
+		number = 10
+	}
+	if (addrType == "") == (ip == "") {
+		fmt.Println("Specify either a type or a subnet")
+		os.Exit(1)
+	}
+
+	options := ipv4subnet.RandomOptions{Unique: unique, Usable: usable}
+	for _, excludeStr := range excludeStrs {
+		prefix, err := parseAddrOrPrefix(excludeStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		options.Exclude = append(options.Exclude, prefix)
+	}
+
+	var addrs []netip.Addr
+	var err error
+	if addrType != "" {
+		addrs, err = ipv4subnet.RandomAddrsOfType(addrType, number, options)
+	} else {
+		// Default to 24 bits
+		if bits == 0 {
+			bits = 24
+		}
+		addrs, err = ipv4subnet.RandomAddrs([]netip.Prefix{parsePrefix(ip, bits)}, number, options)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	for _, addr := range addrs {
+		fmt.Println(addr)
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// RangeToCIDR convert address ranges to the prefixes exactly covering them
+func RangeToCIDR(rangeStrs []string, pretty bool) {
+	ranges := []ipv4subnet.Range{}
+	for _, rangeStr := range inputValues(rangeStrs) {
+		r, err := ipv4subnet.ParseRange(rangeStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		fmt.Println("No ranges supplied")
+		os.Exit(1)
+	}
+
+	if !pretty {
+		for _, r := range ranges {
+			for _, p := range r.Prefixes() {
+				fmt.Println(p.String())
+			}
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Range"},
+			{Align: simpletable.AlignCenter, Text: "Prefix"},
+		},
+	}
+	for _, r := range ranges {
+		for i, p := range r.Prefixes() {
+			label := ""
+			if i == 0 {
+				label = r.String()
+			}
+			table.Body.Cells = append(table.Body.Cells, row(label, p.String()))
+		}
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// IP4SubnetRelate show how two subnets relate to each other
+func IP4SubnetRelate(prefixStrs []string, pretty bool) {
+	if len(prefixStrs) != 2 {
+		fmt.Println("Two prefixes must be supplied")
+		os.Exit(1)
+	}
+
+	subnets := []*ipv4subnet.Subnet{}
+	for _, prefixStr := range prefixStrs {
+		prefix, err := parseAddrOrPrefix(prefixStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		s, err := ipv4subnet.NewFromPrefix(prefix.String())
+		if err != nil {
+			exitWithError(err)
+		}
+		subnets = append(subnets, s)
+	}
+	s, other := subnets[0], subnets[1]
+
+	relation := s.Relate(other)
+	supernet := s.CommonSupernet(other)
+
+	if !pretty {
+		fmt.Printf("%s %s %s\n", s.CIDR(), relation, other.CIDR())
+		fmt.Printf("common %s\n", supernet.CIDR())
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Category"},
+			{Align: simpletable.AlignCenter, Text: "Value"},
+		},
+	}
+	table.Body.Cells = append(table.Body.Cells, row("First", s.CIDR()))
+	table.Body.Cells = append(table.Body.Cells, row("Second", other.CIDR()))
+	table.Body.Cells = append(table.Body.Cells, row("Relation", relation))
+	table.Body.Cells = append(table.Body.Cells, row("Overlaps", s.Overlaps(other)))
+	table.Body.Cells = append(table.Body.Cells, row("Common Prefix Bits", s.CommonPrefixBits(other)))
+	table.Body.Cells = append(table.Body.Cells, row("Common Supernet", supernet.CIDR()))
+	for _, subnet := range subnets {
+		if previous, err := subnet.Previous(); err == nil {
+			table.Body.Cells = append(table.Body.Cells, row(fmt.Sprintf("Previous to %s", subnet.CIDR()), previous.CIDR()))
+		}
+		if next, err := subnet.Next(); err == nil {
+			table.Body.Cells = append(table.Body.Cells, row(fmt.Sprintf("Next to %s", subnet.CIDR()), next.CIDR()))
+		}
+		if sibling, err := subnet.Sibling(); err == nil {
+			table.Body.Cells = append(table.Body.Cells, row(fmt.Sprintf("Sibling of %s", subnet.CIDR()), sibling.CIDR()))
+		}
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+
+	"github.com/imarsman/iptools/pkg/dnszone"
+	"github.com/imarsman/iptools/pkg/ipv6"
+)
+
+// IP4SubnetReverseZone write reverse zones with PTR records for a subnet
+// Names come from a hostname template or from inventory files of address and name
+// pairs. Subnets longer than /24 also get the RFC 2317 records for the parent zone.
+func IP4SubnetReverseZone(ip string, bits int, template string, files []string, format string, nameServers []string, contact string, serial uint32, ttl int, outputDir string) {
+	// Default to 24 bits
+	if bits == 0 {
+		bits = 24
+	}
+
+	prefix := parsePrefix(ip, bits).Masked()
+	// check the family before reading names
+	_, err := dnszone.IP4ZonePrefixes(prefix)
+	if err != nil {
+		exitWithError(err)
+	}
+	soa := zoneSOA(nameServers, contact, serial, ttl)
+	names := zoneNames(prefix, template, files, format)
+
+	zones, err := dnszone.IP4Zones(prefix, names, soa)
+	if err != nil {
+		exitWithError(err)
+	}
+	if prefix.Bits() > 24 {
+		delegation, err := dnszone.IP4Delegation(prefix, soa.NameServers)
+		if err != nil {
+			exitWithError(err)
+		}
+		zones = append(zones, delegation)
+	}
+	writeZones(zones, outputDir)
+}
+
+// IP6ReverseZone write ip6.arpa zones with PTR records for a prefix or list the zones it needs
+// Without a template or files of names only the zone origins are listed, one zone for
+// a prefix on a nibble boundary and one for each nibble aligned prefix inside it
+// otherwise.
+func IP6ReverseZone(ip string, bits int, template string, files []string, format string, nameServers []string, contact string, serial uint32, ttl int, outputDir string) {
+	// Default to 64 bits
+	if bits == 0 {
+		bits = 64
+	}
+
+	prefix := parsePrefix(ip, bits).Masked()
+	prefixes, err := dnszone.IP6ZonePrefixes(prefix)
+	if err != nil {
+		exitWithError(err)
+	}
+	if template == "" && len(files) == 0 {
+		for _, zonePrefix := range prefixes {
+			origin, err := ipv6.PrefixArpa(zonePrefix)
+			if err != nil {
+				exitWithError(err)
+			}
+			fmt.Printf("%s.\n", origin)
+		}
+		return
+	}
+	soa := zoneSOA(nameServers, contact, serial, ttl)
+	names := zoneNames(prefix, template, files, format)
+
+	zones, err := dnszone.IP6Zones(prefix, names, soa)
+	if err != nil {
+		exitWithError(err)
+	}
+	writeZones(zones, outputDir)
+}
+
+// zoneSOA get the SOA for generated zones with defaults for values that are not set
+func zoneSOA(nameServers []string, contact string, serial uint32, ttl int) dnszone.SOA {
+	soa := dnszone.DefaultSOA()
+	if len(nameServers) > 0 {
+		soa.NameServers = []string{}
+		for _, ns := range nameServers {
+			soa.NameServers = append(soa.NameServers, dnszone.Fqdn(ns))
+		}
+	}
+	if contact != "" {
+		soa.Contact = dnszone.Fqdn(contact)
+	}
+	if serial != 0 {
+		soa.Serial = serial
+	}
+	if ttl != 0 {
+		soa.TTL = ttl
+	}
+
+	return soa
+}
+
+// zoneNames get the names for addresses in a prefix from a template or inventory files
+func zoneNames(prefix netip.Prefix, template string, files []string, format string) (names map[netip.Addr]string) {
+	if template != "" && len(files) > 0 {
+		exitWithError(fmt.Errorf("use either a template or files of names, not both"))
+	}
+	var err error
+	if template != "" {
+		names, err = dnszone.TemplateNames(prefix, template)
+	} else {
+		names, err = dnszone.NamedAddresses(readInventoryFiles(files, format))
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+
+	return
+}
+
+// writeZones write zones to stdout separated by blank lines or each to a file in a directory
+// Zones without an SOA hold records for a zone kept elsewhere and get a .records file.
+func writeZones(zones []*dnszone.Zone, outputDir string) {
+	for i, zone := range zones {
+		if outputDir == "" {
+			if i > 0 {
+				fmt.Println()
+			}
+			err := zone.Write(os.Stdout)
+			if err != nil {
+				exitWithError(err)
+			}
+			continue
+		}
+		name := zone.FileName()
+		if zone.SOA == nil {
+			name = name[:len(name)-len(filepath.Ext(name))] + ".records"
+		}
+		path := filepath.Join(outputDir, name)
+		file, err := os.Create(path)
+		if err != nil {
+			exitWithError(err)
+		}
+		err = zone.Write(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(path)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipset"
+)
+
+// parseSet get a set from prefixes, addresses and ranges exiting on error
+func parseSet(values []string) *ipset.IPSet {
+	set, err := ipset.Parse(values...)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	return set
+}
+
+// SetUnion print the addresses in any of the values
+func SetUnion(values []string, showRanges, pretty bool) {
+	set := parseSet(inputValues(values))
+	printSet(set, showRanges, pretty)
+}
+
+// SetIntersect print the addresses in both the values and with
+func SetIntersect(values, with []string, showRanges, pretty bool) {
+	set := parseSet(inputValues(values))
+	printSet(set.Intersect(parseSet(with)), showRanges, pretty)
+}
+
+// SetDiff print the addresses in the values that are not in minus
+func SetDiff(values, minus []string, showRanges, pretty bool) {
+	set := parseSet(inputValues(values))
+	printSet(set.Difference(parseSet(minus)), showRanges, pretty)
+}
+
+// printSet print a set as prefixes or ranges
+func printSet(set *ipset.IPSet, showRanges, pretty bool) {
+	if !pretty {
+		if showRanges {
+			for _, r := range set.Ranges() {
+				fmt.Println(r.String())
+			}
+			return
+		}
+		for _, p := range set.Prefixes() {
+			fmt.Println(p.String())
+		}
+		return
+	}
+
+	table := simpletable.New()
+	if showRanges {
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Start"},
+				{Align: simpletable.AlignCenter, Text: "End"},
+			},
+		}
+		for _, r := range set.Ranges() {
+			table.Body.Cells = append(table.Body.Cells, row(r.First().String(), r.Last().String()))
+		}
+	} else {
+		table.Header = &simpletable.Header{
+			Cells: []*simpletable.Cell{
+				{Align: simpletable.AlignCenter, Text: "Prefixes"},
+			},
+		}
+		for _, p := range set.Prefixes() {
+			cell := []*simpletable.Cell{
+				{Align: simpletable.AlignLeft, Text: p.String()},
+			}
+			table.Body.Cells = append(table.Body.Cells, cell)
+		}
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipsubnet"
+	"github.com/imarsman/iptools/pkg/ipv6"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// defaultBits get the prefix length for an address given without one, 24 for IPV4 and 64 for IPV6
+// An IPV4 mapped IPV6 address gets 120, the mapped form of 24.
+func defaultBits(ip string) int {
+	if addr, err := netip.ParseAddr(strings.TrimSpace(ip)); err == nil && addr.Is4In6() {
+		return 120
+	}
+	if strings.Contains(ip, ":") {
+		return 64
+	}
+
+	return 24
+}
+
+// bigCount format a count of any size with thousands separators
+func bigCount(count *big.Int) string {
+	digits := count.String()
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+
+	return b.String()
+}
+
+// newSubnets get a subnet and the secondary subnet it is split into, which is the subnet if there are no secondary bits
+// A cloud provider, if there is one, must allow both.
+func newSubnets(prefix netip.Prefix, secondaryBits int, providerName string) (s, s2 *ipsubnet.Subnet) {
+	s, err := ipsubnet.New(prefix)
+	if err != nil {
+		exitWithError(err)
+	}
+	s2 = s
+	if secondaryBits != 0 {
+		_, err = s.Networks(secondaryBits)
+		if err != nil {
+			exitWithError(err)
+		}
+		s2, err = ipsubnet.New(netip.PrefixFrom(s.First(), secondaryBits))
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+	if provider := cloudProvider(providerName); provider != nil {
+		err = provider.CheckSubnet(s2.Prefix())
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+
+	return
+}
+
+// subnetRows get table rows describing a subnet and the secondary subnets it splits into
+func subnetRows(s, s2 *ipsubnet.Subnet) (rows [][]*simpletable.Cell) {
+	if s.Is4() {
+		rows = append(rows, row("IP Type", ip4TypeName(s.Prefix())))
+	} else {
+		rows = append(rows, row("IP Type", util.AddrTypeName(s.First())))
+	}
+	rows = append(rows, row("Subnet", s.String()))
+	rows = append(rows, row("First Address", s.First()))
+	rows = append(rows, row("Last Address", s.Last()))
+	rows = append(rows, row("Addresses", bigCount(s.Addresses())))
+	if s.Is4() {
+		rows = append(rows, row("Usable Hosts", bigCount(s.UsableHosts())))
+	}
+	if s2 != s {
+		networks, err := s.Networks(s2.Prefix().Bits())
+		if err != nil {
+			exitWithError(err)
+		}
+		rows = append(rows, row("Secondary Subnet", s2.String()))
+		rows = append(rows, row("Secondary Subnet Last Address", s2.Last()))
+		rows = append(rows, row("Secondary Networks", bigCount(networks)))
+		rows = append(rows, row("Secondary Network Addresses", bigCount(s2.Addresses())))
+	} else if s.Is6() && s.Prefix().Bits() <= 64 {
+		networks, err := s.Networks(64)
+		if err != nil {
+			exitWithError(err)
+		}
+		rows = append(rows, row("/64 Networks", bigCount(networks)))
+	}
+
+	return
+}
+
+// subnetDescribe describe a subnet of either family without IPV4 classful networks
+func subnetDescribe(prefix netip.Prefix, secondaryBits int, providerName string) {
+	s, s2 := newSubnets(prefix, secondaryBits, providerName)
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Category"},
+			{Align: simpletable.AlignCenter, Text: "Value"},
+		},
+	}
+	table.Body.Cells = append(table.Body.Cells, subnetRows(s, s2)...)
+	if s.Is6() {
+		ip6RegistryRows(s.First(), table, &ipv6.IPSummary{})
+		if name, err := ipv6.PrefixArpa(s.Prefix()); err == nil {
+			table.Body.Cells = append(table.Body.Cells, row("ip6.arpa", name))
+		}
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
+
+// subnetRanges list the ranges of the secondary subnets of a subnet of either family
+// Offset skips subnets without iterating over them so paging deep into an IPV6 split is fast.
+func subnetRanges(prefix netip.Prefix, secondaryBits int, offset, limit int, providerName string, pretty bool) {
+	s, s2 := newSubnets(prefix, secondaryBits, providerName)
+	ranges, err := s.RangesFromSeq(s2.Prefix().Bits(), big.NewInt(int64(offset)))
+	if err != nil {
+		exitWithError(err)
+	}
+	ranges = page(ranges, 0, limit)
+
+	if !pretty {
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		for r := range ranges {
+			fmt.Fprintln(out, r.String())
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Category"},
+			{Align: simpletable.AlignCenter, Text: "Value"},
+		},
+	}
+	table.Body.Cells = append(table.Body.Cells, subnetRows(s, s2)...)
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println()
+	fmt.Println(table.String())
+
+	fmt.Println()
+	table = simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Start"},
+			{Align: simpletable.AlignCenter, Text: "End"},
+		},
+	}
+	for r := range ranges {
+		table.Body.Cells = append(table.Body.Cells, row(r.First().String(), r.Last().String()))
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
+
+// subnetDivide list the secondary subnets of a subnet of either family
+// Offset skips subnets without iterating over them so paging deep into an IPV6 split is fast.
+func subnetDivide(prefix netip.Prefix, secondaryBits int, offset, limit int, providerName string, pretty bool) {
+	s, s2 := newSubnets(prefix, secondaryBits, providerName)
+	subnets, err := s.SubnetsFromSeq(s2.Prefix().Bits(), big.NewInt(int64(offset)))
+	if err != nil {
+		exitWithError(err)
+	}
+	subnets = page(subnets, 0, limit)
+
+	if !pretty {
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		for subnet := range subnets {
+			fmt.Fprintln(out, subnet.String())
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Category"},
+			{Align: simpletable.AlignCenter, Text: "Value"},
+		},
+	}
+	table.Body.Cells = append(table.Body.Cells, subnetRows(s, s2)...)
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println()
+	fmt.Println(table.String())
+
+	fmt.Println()
+	table = simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Subnets"},
+		},
+	}
+	for subnet := range subnets {
+		table.Body.Cells = append(table.Body.Cells, []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: subnet.String()},
+		})
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
+
+// ip6Prefix parse a prefix for an ip6 command, 64 bits if no length is given
+func ip6Prefix(ip string, bits int) netip.Prefix {
+	if bits == 0 {
+		bits = 64
+	}
+	prefix := parsePrefix(ip, bits)
+	if !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		exitWithError(fmt.Errorf("%w: %s is not an IPV6 prefix", util.ErrWrongFamily, prefix))
+	}
+
+	return prefix
+}
+
+// IP6SubnetRanges list the ranges of the subnets an IPV6 prefix divides into
+func IP6SubnetRanges(ip string, bits, secondaryBits, offset, limit int, pretty bool) {
+	subnetRanges(ip6Prefix(ip, bits), secondaryBits, offset, limit, "", pretty)
+}
+
+// IP6SubnetDivide list the subnets an IPV6 prefix divides into
+func IP6SubnetDivide(ip string, bits, secondaryBits, offset, limit int, pretty bool) {
+	subnetDivide(ip6Prefix(ip, bits), secondaryBits, offset, limit, "", pretty)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// Summarize collapse a list of prefixes into the minimal covering set
+func Summarize(prefixStrs []string, pretty bool) {
+	prefixes := []netip.Prefix{}
+	for _, prefixStr := range inputValues(prefixStrs) {
+		prefix, err := parseAddrOrPrefix(prefixStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	if len(prefixes) == 0 {
+		fmt.Println("No prefixes supplied")
+		os.Exit(1)
+	}
+
+	summary := ipv4subnet.Summarize(prefixes)
+
+	if !pretty {
+		for _, p := range summary {
+			fmt.Println(p.String())
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Category"},
+			{Align: simpletable.AlignCenter, Text: "Value"},
+		},
+	}
+	table.Body.Cells = append(table.Body.Cells, row("Prefixes", len(prefixes)))
+	table.Body.Cells = append(table.Body.Cells, row("Summarized Prefixes", len(summary)))
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println()
+	fmt.Println(table.String())
+
+	fmt.Println()
+	table = simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Summary"},
+		},
+	}
+	for _, p := range summary {
+		cell := []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: p.String()},
+		}
+		table.Body.Cells = append(table.Body.Cells, cell)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+const (
+	// treeText indented tree for the terminal
+	treeText = "text"
+	// treeDOT Graphviz DOT
+	treeDOT = "dot"
+	// treeMermaid Mermaid flowchart
+	treeMermaid = "mermaid"
+)
+
+// treeColours fill colours for node states in DOT and Mermaid output
+var treeColours = map[string]string{
+	ipv4subnet.FreeState:      "#c8e6c9",
+	ipv4subnet.AllocatedState: "#ffcdd2",
+	ipv4subnet.PartialState:   "#fff9c4",
+}
+
+// IP4SubnetTree show a subnet split in halves down to secondary bits
+// Allocations come from args and from inventory files.
+func IP4SubnetTree(ip string, bits, secondaryBits int, allocatedStrs, files []string, inventoryFormat, format string, compact bool) {
+	// Default to 24 bits
+	if bits == 0 {
+		bits = 24
+	}
+
+	prefix := parsePrefix(ip, bits)
+
+	s, err := ipv4subnet.NewFromPrefix(prefix.String())
+	if err != nil {
+		exitWithError(err)
+	}
+	if secondaryBits == 0 {
+		secondaryBits = min(s.Prefix().Bits()+2, 32)
+	}
+
+	allocated := []ipv4subnet.NamedPrefix{}
+	for _, allocatedStr := range allocatedStrs {
+		namedPrefix, err := ipv4subnet.ParseNamedPrefix(allocatedStr, "")
+		if err != nil {
+			exitWithError(err)
+		}
+		allocated = append(allocated, namedPrefix)
+	}
+	if len(files) > 0 {
+		allocated = append(allocated, readInventoryFiles(files, inventoryFormat)...)
+	}
+
+	root, err := s.Tree(secondaryBits, allocated, compact)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+
+	switch format {
+	case treeText, "":
+		writeTextTree(writer, root, "", "")
+	case treeDOT:
+		writeDOTTree(writer, root)
+	case treeMermaid:
+		writeMermaidTree(writer, root)
+	default:
+		fmt.Printf("Unknown format %s, expected text, dot or mermaid\n", format)
+		os.Exit(1)
+	}
+}
+
+// treeLabel get the CIDR, state and allocation name for a node
+func treeLabel(node *ipv4subnet.TreeNode) string {
+	label := fmt.Sprintf("%s %s", node.Subnet.CIDR(), node.State)
+	if node.Name != "" {
+		label = fmt.Sprintf("%s %s", label, node.Name)
+	}
+
+	return label
+}
+
+// writeTextTree write a node and the nodes below it with box drawing lines
+func writeTextTree(writer *bufio.Writer, node *ipv4subnet.TreeNode, first, rest string) {
+	fmt.Fprintf(writer, "%s%s\n", first, treeLabel(node))
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			writeTextTree(writer, child, rest+"└── ", rest+"    ")
+		} else {
+			writeTextTree(writer, child, rest+"├── ", rest+"│   ")
+		}
+	}
+}
+
+// writeDOTTree write a tree as a Graphviz digraph
+func writeDOTTree(writer *bufio.Writer, root *ipv4subnet.TreeNode) {
+	fmt.Fprintln(writer, "digraph subnets {")
+	fmt.Fprintln(writer, "  node [shape=box, style=filled, fontname=monospace];")
+	root.Walk(func(node *ipv4subnet.TreeNode, depth int) {
+		label := strings.ReplaceAll(treeLabel(node), `"`, `\"`)
+		label = strings.Replace(label, " ", `\n`, 1)
+		fmt.Fprintf(writer, "  %q [label=\"%s\", fillcolor=%q];\n", node.Subnet.CIDR(), label, treeColours[node.State])
+		for _, child := range node.Children {
+			fmt.Fprintf(writer, "  %q -> %q;\n", node.Subnet.CIDR(), child.Subnet.CIDR())
+		}
+	})
+	fmt.Fprintln(writer, "}")
+}
+
+// writeMermaidTree write a tree as a Mermaid flowchart
+// Mermaid ids can't hold dots or slashes so nodes are numbered in walk order.
+func writeMermaidTree(writer *bufio.Writer, root *ipv4subnet.TreeNode) {
+	fmt.Fprintln(writer, "graph TD")
+	ids := make(map[*ipv4subnet.TreeNode]string)
+	root.Walk(func(node *ipv4subnet.TreeNode, depth int) {
+		ids[node] = fmt.Sprintf("n%d", len(ids))
+		label := strings.ReplaceAll(treeLabel(node), `"`, "#quot;")
+		label = strings.Replace(label, " ", "<br/>", 1)
+		fmt.Fprintf(writer, "  %s[\"%s\"]:::%s\n", ids[node], label, node.State)
+	})
+	root.Walk(func(node *ipv4subnet.TreeNode, depth int) {
+		for _, child := range node.Children {
+			fmt.Fprintf(writer, "  %s --> %s\n", ids[node], ids[child])
+		}
+	})
+	for _, state := range []string{ipv4subnet.FreeState, ipv4subnet.AllocatedState, ipv4subnet.PartialState} {
+		fmt.Fprintf(writer, "  classDef %s fill:%s\n", state, treeColours[state])
+	}
+}
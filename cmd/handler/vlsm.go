@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexeyco/simpletable"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+)
+
+// IP4SubnetVLSM allocate variable sized subnets from a subnet by host requirements
+func IP4SubnetVLSM(ip string, bits int, requirementStrs []string, pretty bool) {
+	// Default to 24 bits
+	if bits == 0 {
+		bits = 24
+	}
+
+	prefix := parsePrefix(ip, bits)
+
+	s, err := ipv4subnet.NewFromPrefix(prefix.String())
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if len(requirementStrs) == 0 {
+		fmt.Println("No requirements supplied")
+		os.Exit(1)
+	}
+
+	requirements := []ipv4subnet.Requirement{}
+	hostsForName := make(map[string]int64)
+	for _, requirementStr := range requirementStrs {
+		requirement, err := ipv4subnet.ParseRequirement(requirementStr)
+		if err != nil {
+			exitWithError(err)
+		}
+		requirements = append(requirements, requirement)
+		hostsForName[requirement.Name] = requirement.Hosts
+	}
+
+	allocated, free, err := s.VLSM(requirements)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if !pretty {
+		for _, a := range allocated {
+			fmt.Printf("%s %s\n", a.Name(), a.String())
+		}
+		for _, f := range free {
+			fmt.Printf("free %s\n", f.String())
+		}
+		return
+	}
+
+	table := simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Name"},
+			{Align: simpletable.AlignCenter, Text: "Subnet"},
+			{Align: simpletable.AlignCenter, Text: "Required Hosts"},
+			{Align: simpletable.AlignCenter, Text: "Usable Hosts"},
+			{Align: simpletable.AlignCenter, Text: "Usable Range"},
+			{Align: simpletable.AlignCenter, Text: "Broadcast Address"},
+		},
+	}
+	for _, a := range allocated {
+		first, _ := a.First()
+		cells := []*simpletable.Cell{
+			{Align: simpletable.AlignLeft, Text: a.Name()},
+			{Align: simpletable.AlignLeft, Text: a.String()},
+			{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", hostsForName[a.Name()])},
+			{Align: simpletable.AlignRight, Text: printer.Sprintf("%d", a.UsableHosts())},
+			{Align: simpletable.AlignLeft, Text: fmt.Sprintf("%s-%s", first.Next(), a.BroadcastAddr().Prev())},
+			{Align: simpletable.AlignLeft, Text: a.BroadcastAddr().String()},
+		}
+		table.Body.Cells = append(table.Body.Cells, cells)
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+
+	fmt.Println()
+	table = simpletable.New()
+	table.Header = &simpletable.Header{
+		Cells: []*simpletable.Cell{
+			{Align: simpletable.AlignCenter, Text: "Free"},
+			{Align: simpletable.AlignCenter, Text: "Addresses"},
+		},
+	}
+	for _, f := range free {
+		table.Body.Cells = append(table.Body.Cells, row(f.String(), printer.Sprintf("%d", f.Hosts())))
+	}
+	table.SetStyle(simpletable.StyleCompactLite)
+	fmt.Println(table.String())
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/alexflint/go-arg"
 	"github.com/imarsman/iptools/cmd/args"
 	"github.com/imarsman/iptools/cmd/handler"
+	"github.com/imarsman/iptools/pkg/export"
 )
 
 // the new comparable package is not there in 1.18
@@ -30,6 +31,8 @@ func dedup[T comparable](slice []T) (result []T) {
 
 func main() {
 	args.InitializeCompletion()
+	// go-arg keeps only the last use of a list flag so gather repeated uses first
+	os.Args = append([]string{os.Args[0]}, args.MergeRepeatedFlags(os.Args[1:])...)
 	arg.MustParse(&args.CLIArgs)
 
 	// Inspect cli args and make calls to handlers as apppropriate
@@ -39,6 +42,9 @@ func main() {
 				args.CLIArgs.IP4Subnet.SubnetRanges.IP,
 				args.CLIArgs.IP4Subnet.SubnetRanges.Bits,
 				args.CLIArgs.IP4Subnet.SubnetRanges.SecondaryBits,
+				args.CLIArgs.IP4Subnet.SubnetRanges.Offset,
+				args.CLIArgs.IP4Subnet.SubnetRanges.Limit,
+				args.CLIArgs.IP4Subnet.SubnetRanges.Provider,
 			)
 		}
 		if args.CLIArgs.IP4Subnet.SubnetDivide != nil {
@@ -46,6 +52,9 @@ func main() {
 				args.CLIArgs.IP4Subnet.SubnetDivide.IP,
 				args.CLIArgs.IP4Subnet.SubnetDivide.Bits,
 				args.CLIArgs.IP4Subnet.SubnetDivide.SecondaryBits,
+				args.CLIArgs.IP4Subnet.SubnetDivide.Offset,
+				args.CLIArgs.IP4Subnet.SubnetDivide.Limit,
+				args.CLIArgs.IP4Subnet.SubnetDivide.Provider,
 			)
 		}
 		if args.CLIArgs.IP4Subnet.SubnetDescribe != nil {
@@ -53,6 +62,92 @@ func main() {
 				args.CLIArgs.IP4Subnet.SubnetDescribe.IP,
 				args.CLIArgs.IP4Subnet.SubnetDescribe.Bits,
 				args.CLIArgs.IP4Subnet.SubnetDescribe.SecondaryBits,
+				args.CLIArgs.IP4Subnet.SubnetDescribe.Provider,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.SubnetVLSM != nil {
+			handler.IP4SubnetVLSM(
+				args.CLIArgs.IP4Subnet.SubnetVLSM.IP,
+				args.CLIArgs.IP4Subnet.SubnetVLSM.Bits,
+				args.CLIArgs.IP4Subnet.SubnetVLSM.Requirements,
+				args.CLIArgs.IP4Subnet.SubnetVLSM.Pretty,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.SubnetRelate != nil {
+			handler.IP4SubnetRelate(
+				args.CLIArgs.IP4Subnet.SubnetRelate.Prefixes,
+				args.CLIArgs.IP4Subnet.SubnetRelate.Pretty,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.SubnetFree != nil {
+			handler.IP4SubnetFree(
+				args.CLIArgs.IP4Subnet.SubnetFree.IP,
+				args.CLIArgs.IP4Subnet.SubnetFree.Bits,
+				args.CLIArgs.IP4Subnet.SubnetFree.Allocated,
+				args.CLIArgs.IP4Subnet.SubnetFree.Files,
+				args.CLIArgs.IP4Subnet.SubnetFree.Format,
+				args.CLIArgs.IP4Subnet.SubnetFree.Size,
+				args.CLIArgs.IP4Subnet.SubnetFree.BestFit,
+				args.CLIArgs.IP4Subnet.SubnetFree.Pretty,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.SubnetTree != nil {
+			handler.IP4SubnetTree(
+				args.CLIArgs.IP4Subnet.SubnetTree.IP,
+				args.CLIArgs.IP4Subnet.SubnetTree.Bits,
+				args.CLIArgs.IP4Subnet.SubnetTree.SecondaryBits,
+				args.CLIArgs.IP4Subnet.SubnetTree.Allocated,
+				args.CLIArgs.IP4Subnet.SubnetTree.Files,
+				args.CLIArgs.IP4Subnet.SubnetTree.InventoryFormat,
+				args.CLIArgs.IP4Subnet.SubnetTree.Format,
+				args.CLIArgs.IP4Subnet.SubnetTree.Compact,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.SubnetReverse != nil {
+			handler.IP4SubnetReverseZone(
+				args.CLIArgs.IP4Subnet.SubnetReverse.IP,
+				args.CLIArgs.IP4Subnet.SubnetReverse.Bits,
+				args.CLIArgs.IP4Subnet.SubnetReverse.Template,
+				args.CLIArgs.IP4Subnet.SubnetReverse.Files,
+				args.CLIArgs.IP4Subnet.SubnetReverse.Format,
+				args.CLIArgs.IP4Subnet.SubnetReverse.NameServers,
+				args.CLIArgs.IP4Subnet.SubnetReverse.Contact,
+				args.CLIArgs.IP4Subnet.SubnetReverse.Serial,
+				args.CLIArgs.IP4Subnet.SubnetReverse.TTL,
+				args.CLIArgs.IP4Subnet.SubnetReverse.OutputDir,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.RandomIPs != nil {
+			handler.IP4RandomIPs(
+				args.CLIArgs.IP4Subnet.RandomIPs.Type,
+				args.CLIArgs.IP4Subnet.RandomIPs.IP,
+				args.CLIArgs.IP4Subnet.RandomIPs.Bits,
+				args.CLIArgs.IP4Subnet.RandomIPs.Number,
+				args.CLIArgs.IP4Subnet.RandomIPs.Unique,
+				args.CLIArgs.IP4Subnet.RandomIPs.Usable,
+				args.CLIArgs.IP4Subnet.RandomIPs.Exclude,
+			)
+		}
+		if args.CLIArgs.IP4Subnet.Export != nil {
+			handler.IP4SubnetExport(
+				args.CLIArgs.IP4Subnet.Export.IP,
+				args.CLIArgs.IP4Subnet.Export.Bits,
+				args.CLIArgs.IP4Subnet.Export.SecondaryBits,
+				args.CLIArgs.IP4Subnet.Export.Files,
+				args.CLIArgs.IP4Subnet.Export.Format,
+				args.CLIArgs.IP4Subnet.Export.Target,
+				args.CLIArgs.IP4Subnet.Export.TemplateFiles,
+				args.CLIArgs.IP4Subnet.Export.List,
+				export.Options{
+					Name:      args.CLIArgs.IP4Subnet.Export.Name,
+					Action:    args.CLIArgs.IP4Subnet.Export.Action,
+					GE:        args.CLIArgs.IP4Subnet.Export.GE,
+					LE:        args.CLIArgs.IP4Subnet.Export.LE,
+					Seq:       args.CLIArgs.IP4Subnet.Export.Seq,
+					SeqStep:   args.CLIArgs.IP4Subnet.Export.SeqStep,
+					NextHop:   args.CLIArgs.IP4Subnet.Export.NextHop,
+					Interface: args.CLIArgs.IP4Subnet.Export.Interface,
+				},
 			)
 		}
 	}
@@ -76,6 +171,181 @@ func main() {
 				args.CLIArgs.IP6Subnet.IP6RandomIPs.Number,
 			)
 		}
+		if args.CLIArgs.IP6Subnet.IP6ReverseZone != nil {
+			handler.IP6ReverseZone(
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.IP,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.Bits,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.Template,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.Files,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.Format,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.NameServers,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.Contact,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.Serial,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.TTL,
+				args.CLIArgs.IP6Subnet.IP6ReverseZone.OutputDir,
+			)
+		}
+		if args.CLIArgs.IP6Subnet.IP6SubnetRanges != nil {
+			handler.IP6SubnetRanges(
+				args.CLIArgs.IP6Subnet.IP6SubnetRanges.IP,
+				args.CLIArgs.IP6Subnet.IP6SubnetRanges.Bits,
+				args.CLIArgs.IP6Subnet.IP6SubnetRanges.SecondaryBits,
+				args.CLIArgs.IP6Subnet.IP6SubnetRanges.Offset,
+				args.CLIArgs.IP6Subnet.IP6SubnetRanges.Limit,
+				args.CLIArgs.IP6Subnet.IP6SubnetRanges.Pretty,
+			)
+		}
+		if args.CLIArgs.IP6Subnet.IP6SubnetDivide != nil {
+			handler.IP6SubnetDivide(
+				args.CLIArgs.IP6Subnet.IP6SubnetDivide.IP,
+				args.CLIArgs.IP6Subnet.IP6SubnetDivide.Bits,
+				args.CLIArgs.IP6Subnet.IP6SubnetDivide.SecondaryBits,
+				args.CLIArgs.IP6Subnet.IP6SubnetDivide.Offset,
+				args.CLIArgs.IP6Subnet.IP6SubnetDivide.Limit,
+				args.CLIArgs.IP6Subnet.IP6SubnetDivide.Pretty,
+			)
+		}
+	}
+	if args.CLIArgs.Summarize != nil {
+		handler.Summarize(args.CLIArgs.Summarize.Prefixes, args.CLIArgs.Summarize.Pretty)
+	}
+	if args.CLIArgs.RangeToCIDR != nil {
+		handler.RangeToCIDR(args.CLIArgs.RangeToCIDR.Ranges, args.CLIArgs.RangeToCIDR.Pretty)
+	}
+	if args.CLIArgs.FromArpa != nil {
+		handler.FromArpa(args.CLIArgs.FromArpa.Names, args.CLIArgs.FromArpa.Pretty)
+	}
+	if args.CLIArgs.Set != nil {
+		if args.CLIArgs.Set.Union != nil {
+			handler.SetUnion(
+				args.CLIArgs.Set.Union.Values,
+				args.CLIArgs.Set.Union.Ranges,
+				args.CLIArgs.Set.Union.Pretty,
+			)
+		}
+		if args.CLIArgs.Set.Intersect != nil {
+			handler.SetIntersect(
+				args.CLIArgs.Set.Intersect.Values,
+				args.CLIArgs.Set.Intersect.With,
+				args.CLIArgs.Set.Intersect.Ranges,
+				args.CLIArgs.Set.Intersect.Pretty,
+			)
+		}
+		if args.CLIArgs.Set.Diff != nil {
+			handler.SetDiff(
+				args.CLIArgs.Set.Diff.Values,
+				args.CLIArgs.Set.Diff.Minus,
+				args.CLIArgs.Set.Diff.Ranges,
+				args.CLIArgs.Set.Diff.Pretty,
+			)
+		}
+	}
+	if args.CLIArgs.CheckOverlaps != nil {
+		handler.CheckOverlaps(
+			args.CLIArgs.CheckOverlaps.Files,
+			args.CLIArgs.CheckOverlaps.Format,
+			args.CLIArgs.CheckOverlaps.JSON,
+			args.CLIArgs.CheckOverlaps.YAML,
+		)
+	}
+	if args.CLIArgs.Plan != nil {
+		if args.CLIArgs.Plan.Validate != nil {
+			handler.PlanValidate(args.CLIArgs.Plan.Validate.File)
+		}
+		if args.CLIArgs.Plan.Render != nil {
+			handler.PlanRender(
+				args.CLIArgs.Plan.Render.File,
+				args.CLIArgs.Plan.Render.Format,
+			)
+		}
+	}
+	if args.CLIArgs.Cloud != nil {
+		if args.CLIArgs.Cloud.Plan != nil {
+			handler.CloudPlan(
+				args.CLIArgs.Cloud.Plan.Provider,
+				args.CLIArgs.Cloud.Plan.IP,
+				args.CLIArgs.Cloud.Plan.Bits,
+				args.CLIArgs.Cloud.Plan.Zones,
+				args.CLIArgs.Cloud.Plan.AZs,
+				args.CLIArgs.Cloud.Plan.Tiers,
+				args.CLIArgs.Cloud.Plan.Pretty,
+			)
+		}
+	}
+	if args.CLIArgs.K8s != nil {
+		if args.CLIArgs.K8s.Plan != nil {
+			handler.K8sPlan(
+				args.CLIArgs.K8s.Plan.ClusterCIDRs,
+				args.CLIArgs.K8s.Plan.NodeMask,
+				args.CLIArgs.K8s.Plan.NodeMaskIPv4,
+				args.CLIArgs.K8s.Plan.NodeMaskIPv6,
+				args.CLIArgs.K8s.Plan.ServiceCIDRs,
+				args.CLIArgs.K8s.Plan.Nodes,
+				args.CLIArgs.K8s.Plan.MaxPods,
+				args.CLIArgs.K8s.Plan.NodeNetworks,
+				args.CLIArgs.K8s.Plan.VPCs,
+				args.CLIArgs.K8s.Plan.Pretty,
+			)
+		}
+	}
+	if args.CLIArgs.DHCPConfig != nil {
+		handler.DHCPConfig(
+			args.CLIArgs.DHCPConfig.IP,
+			args.CLIArgs.DHCPConfig.Bits,
+			args.CLIArgs.DHCPConfig.Format,
+			args.CLIArgs.DHCPConfig.Gateway,
+			args.CLIArgs.DHCPConfig.Exclude,
+			args.CLIArgs.DHCPConfig.DNS,
+			args.CLIArgs.DHCPConfig.LeaseTime,
+		)
+	}
+	if args.CLIArgs.IPAM != nil {
+		file := args.CLIArgs.IPAM.File
+		if args.CLIArgs.IPAM.Init != nil {
+			handler.IPAMInit(
+				file,
+				args.CLIArgs.IPAM.Init.Pool,
+				args.CLIArgs.IPAM.Init.Prefix,
+			)
+		}
+		if args.CLIArgs.IPAM.Allocate != nil {
+			handler.IPAMAllocate(
+				file,
+				args.CLIArgs.IPAM.Allocate.Pool,
+				args.CLIArgs.IPAM.Allocate.Name,
+				args.CLIArgs.IPAM.Allocate.Size,
+				args.CLIArgs.IPAM.Allocate.Prefix,
+				args.CLIArgs.IPAM.Allocate.IP,
+				args.CLIArgs.IPAM.Allocate.NextIP,
+				args.CLIArgs.IPAM.Allocate.Subnet,
+				args.CLIArgs.IPAM.Allocate.BestFit,
+			)
+		}
+		if args.CLIArgs.IPAM.Release != nil {
+			handler.IPAMRelease(
+				file,
+				args.CLIArgs.IPAM.Release.Pool,
+				args.CLIArgs.IPAM.Release.Name,
+				args.CLIArgs.IPAM.Release.IP,
+			)
+		}
+		if args.CLIArgs.IPAM.List != nil {
+			handler.IPAMList(
+				file,
+				args.CLIArgs.IPAM.List.Pool,
+				args.CLIArgs.IPAM.List.Pretty,
+				args.CLIArgs.IPAM.List.JSON,
+				args.CLIArgs.IPAM.List.YAML,
+			)
+		}
+		if args.CLIArgs.IPAM.Show != nil {
+			handler.IPAMShow(
+				file,
+				args.CLIArgs.IPAM.Show.Pool,
+				args.CLIArgs.IPAM.Show.JSON,
+				args.CLIArgs.IPAM.Show.YAML,
+			)
+		}
 	}
 	if args.CLIArgs.Utilities != nil {
 		if len(args.CLIArgs.Utilities.Lookup.Domains) != 0 {
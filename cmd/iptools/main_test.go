@@ -3,11 +3,46 @@ package main
 import (
 	"encoding/json"
 	"net"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
 )
 
+// runMainEnv set in the environment of a test binary that should run main
+const runMainEnv = "IPTOOLS_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runIPTools run iptools with arguments by running the test binary as main and get its output
+func runIPTools(t *testing.T, arguments string) string {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], strings.Fields(arguments)...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	t.Logf("iptools %s\n%s", arguments, out)
+	if err != nil {
+		t.Fatalf("iptools %s: %v", arguments, err)
+	}
+
+	return string(out)
+}
+
+func TestRepeatedFlags(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "subnetip4 vlsm -i 10.0.0.0/22 -r a:500 -r b:60 -r c:2 -r d:2")
+	is.True(strings.HasPrefix(out, "a 10.0.0.0/23\nb 10.0.2.0/26\nc 10.0.2.64/30\nd 10.0.2.68/30\n"))
+}
+
 func expandInterfaceToMatch(i interface{}) interface{} {
 	switch x := i.(type) {
 	case map[interface{}]interface{}:
@@ -91,3 +126,71 @@ func TestEncodeIP(t *testing.T) {
 // 	cidr, _ := ipMask.Size()
 // 	t.Log("cidr", cidr)
 // }
+
+func TestSetDiffRepeatedMinus(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "set diff 10.0.0.0/8 --minus 10.1.0.0/16 --minus 10.2.0.0/15")
+	is.Equal(out, "10.0.0.0/16\n10.4.0.0/14\n10.8.0.0/13\n10.16.0.0/12\n10.32.0.0/11\n10.64.0.0/10\n10.128.0.0/9\n")
+}
+
+func TestFreeRepeatedAllocated(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "subnetip4 free -i 10.20.0.0/16 -a 10.20.0.0/24 -a 10.20.5.0/24")
+	is.True(strings.HasPrefix(out, "10.20.1.0/24\n10.20.2.0/23\n10.20.4.0/24\n10.20.6.0/23\n"))
+}
+
+func TestTreeRepeatedAllocated(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "subnetip4 tree -i 10.0.0.0/22 -s 24 -a 10.0.0.0/24 -a 10.0.3.0/24")
+	is.True(strings.Contains(out, "10.0.0.0/24 allocated"))
+	is.True(strings.Contains(out, "10.0.3.0/24 allocated"))
+}
+
+func TestReverseZoneRepeatedNameServers(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "subnetip4 reverse-zone -i 192.0.2.0/24 --ns ns1.ex.net --ns ns2.ex.net --serial 2026101801")
+	is.True(strings.Contains(out, "@\tIN\tSOA\tns1.ex.net. "))
+	is.True(strings.Contains(out, "@\tIN\tNS\tns1.ex.net.\n@\tIN\tNS\tns2.ex.net.\n"))
+}
+
+func TestCloudPlanRepeatedZonesAndTiers(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "cloud plan --provider aws -i 10.0.0.0/16 --zone a --zone b --tier web:1 --tier db:1")
+	is.Equal(out, "web a 10.0.0.0/18 16379\nweb b 10.0.64.0/18 16379\ndb a 10.0.128.0/18 16379\ndb b 10.0.192.0/18 16379\n")
+}
+
+func TestK8sPlanRepeatedCIDRs(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "k8s plan -c 10.244.0.0/16 -c fd00:10:244::/56 -s 10.96.0.0/12 -s fd00:10:96::/108")
+	is.True(strings.Contains(out, "cluster 10.244.0.0/16 node-mask 24"))
+	is.True(strings.Contains(out, "cluster fd00:10:244::/56 node-mask 64"))
+	is.True(strings.Contains(out, "service 10.96.0.0/12 "))
+	is.True(strings.Contains(out, "service fd00:10:96::/108 "))
+}
+
+func TestDHCPConfigRepeatedExcludeAndDNS(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "dhcp-config -i 192.168.10.0/24 -f isc -x 192.168.10.100-192.168.10.120 -x 192.168.10.1 -d 1.1.1.1 -d 8.8.8.8")
+	is.True(strings.Contains(out, "  range 192.168.10.2 192.168.10.99;\n  range 192.168.10.121 192.168.10.254;\n"))
+	is.True(strings.Contains(out, "  option domain-name-servers 1.1.1.1, 8.8.8.8;\n"))
+}
+
+func TestSubnetMappedPrefix(t *testing.T) {
+	is := is.New(t)
+
+	out := runIPTools(t, "subnetip4 describe -ip ::ffff:10.0.0.0/120")
+	is.True(strings.Contains(out, " Subnet                     10.0.0.0/24 "))
+	out = runIPTools(t, "subnetip4 describe -ip ::ffff:10.0.0.7")
+	is.True(strings.Contains(out, " Subnet                     10.0.0.0/24 "))
+	out = runIPTools(t, "subnetip4 ranges -ip ::ffff:10.0.0.0/120 -secondary-bits 122")
+	is.Equal(out, "10.0.0.0-10.0.0.63\n10.0.0.64-10.0.0.127\n10.0.0.128-10.0.0.191\n10.0.0.192-10.0.0.255\n")
+	out = runIPTools(t, "subnetip4 divide -ip ::ffff:10.0.0.0/120 -secondary-bits 26")
+	is.Equal(out, "10.0.0.0/26\n10.0.0.64/26\n10.0.0.128/26\n10.0.0.192/26\n")
+}
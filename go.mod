@@ -1,6 +1,6 @@
 module github.com/imarsman/iptools
 
-go 1.19
+go 1.23
 
 require (
 	github.com/alexeyco/simpletable v1.0.0
@@ -0,0 +1,189 @@
+package cloud
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// Reservation an address a provider keeps in every subnet
+// A negative offset counts back from the end of the subnet, -1 being the last address.
+type Reservation struct {
+	Offset  int
+	Purpose string
+}
+
+// ReservedAddr an address reserved in a particular subnet
+type ReservedAddr struct {
+	Addr    netip.Addr
+	Purpose string
+}
+
+// Provider the rules a cloud provider has for IPV4 networks and their subnets
+// Limits are prefix lengths so MinBits is the largest allowed block and MaxBits the
+// smallest.
+type Provider struct {
+	Name           string
+	Title          string
+	NetworkName    string
+	Reserved       []Reservation
+	SubnetMinBits  int
+	SubnetMaxBits  int
+	NetworkMinBits int
+	NetworkMaxBits int
+}
+
+var providers = map[string]*Provider{
+	"aws": {
+		Name:        "aws",
+		Title:       "AWS",
+		NetworkName: "VPC",
+		Reserved: []Reservation{
+			{0, "network address"},
+			{1, "VPC router"},
+			{2, "DNS server"},
+			{3, "reserved for future use"},
+			{-1, "broadcast address"},
+		},
+		SubnetMinBits:  16,
+		SubnetMaxBits:  28,
+		NetworkMinBits: 16,
+		NetworkMaxBits: 28,
+	},
+	"azure": {
+		Name:        "azure",
+		Title:       "Azure",
+		NetworkName: "VNet",
+		Reserved: []Reservation{
+			{0, "network address"},
+			{1, "default gateway"},
+			{2, "Azure DNS"},
+			{3, "Azure DNS"},
+			{-1, "broadcast address"},
+		},
+		SubnetMinBits:  2,
+		SubnetMaxBits:  29,
+		NetworkMinBits: 2,
+		NetworkMaxBits: 29,
+	},
+	"gcp": {
+		Name:        "gcp",
+		Title:       "GCP",
+		NetworkName: "VPC",
+		Reserved: []Reservation{
+			{0, "network address"},
+			{1, "default gateway"},
+			{-2, "reserved for future use"},
+			{-1, "broadcast address"},
+		},
+		SubnetMinBits:  4,
+		SubnetMaxBits:  29,
+		NetworkMinBits: 4,
+		NetworkMaxBits: 29,
+	},
+}
+
+// Providers get the providers sorted by name
+func Providers() (list []*Provider) {
+	for _, provider := range providers {
+		list = append(list, provider)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return
+}
+
+// ProviderNames get the names of the providers
+func ProviderNames() (names []string) {
+	for _, provider := range Providers() {
+		names = append(names, provider.Name)
+	}
+
+	return
+}
+
+// ProviderByName get a provider such as aws, azure or gcp
+func ProviderByName(name string) (provider *Provider, err error) {
+	provider, ok := providers[strings.ToLower(name)]
+	if !ok {
+		err = fmt.Errorf("unknown provider %s, expected one of %v", name, ProviderNames())
+	}
+
+	return
+}
+
+// CheckSubnet check that a prefix is an IPV4 prefix the provider allows for a subnet
+func (p *Provider) CheckSubnet(prefix netip.Prefix) error {
+	return p.check(prefix, "subnets", p.SubnetMinBits, p.SubnetMaxBits)
+}
+
+// CheckNetwork check that a prefix is an IPV4 prefix the provider allows for a VPC or VNet
+func (p *Provider) CheckNetwork(prefix netip.Prefix) error {
+	return p.check(prefix, p.NetworkName+"s", p.NetworkMinBits, p.NetworkMaxBits)
+}
+
+// check check a prefix against prefix length limits
+func (p *Provider) check(prefix netip.Prefix, kind string, minBits, maxBits int) error {
+	if !prefix.Addr().Is4() {
+		return fmt.Errorf("%w: %s is not an IPV4 prefix", util.ErrWrongFamily, prefix)
+	}
+	if prefix.Bits() < minBits || prefix.Bits() > maxBits {
+		return fmt.Errorf("%w: %s %s must be /%d to /%d and %s is /%d",
+			util.ErrInvalidPrefix, p.Title, kind, minBits, maxBits, prefix.Masked(), prefix.Bits())
+	}
+
+	return nil
+}
+
+// ReservedAddrs get the addresses the provider reserves in a subnet in address order
+func (p *Provider) ReservedAddrs(prefix netip.Prefix) (addrs []ReservedAddr) {
+	prefix = prefix.Masked()
+	first := ipv4util.AddrToUint32(prefix.Addr())
+	last := first + uint32(1<<(32-prefix.Bits())-1)
+	for _, reservation := range p.Reserved {
+		value := first + uint32(reservation.Offset)
+		if reservation.Offset < 0 {
+			value = last + uint32(reservation.Offset+1)
+		}
+		addrs = append(addrs, ReservedAddr{Addr: ipv4util.Uint32ToAddr(value), Purpose: reservation.Purpose})
+	}
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return addrs[i].Addr.Less(addrs[j].Addr)
+	})
+
+	return
+}
+
+// UsableHosts get the number of addresses in a subnet that can be given to hosts
+func (p *Provider) UsableHosts(prefix netip.Prefix) int64 {
+	return int64(1)<<(32-prefix.Bits()) - int64(len(p.Reserved))
+}
+
+// UsableRange get the range of addresses in a subnet between the reserved addresses
+func (p *Provider) UsableRange(prefix netip.Prefix) (r ipv4subnet.Range, err error) {
+	err = p.CheckSubnet(prefix)
+	if err != nil {
+		return
+	}
+	prefix = prefix.Masked()
+	start := ipv4util.AddrToUint32(prefix.Addr())
+	end := start + uint32(1<<(32-prefix.Bits())-1)
+	first, last := start, end
+	for _, reservation := range p.Reserved {
+		if reservation.Offset >= 0 {
+			first = max(first, start+uint32(reservation.Offset)+1)
+		} else {
+			last = min(last, end+uint32(reservation.Offset))
+		}
+	}
+	r = ipv4subnet.NewRange(ipv4util.Uint32ToAddr(first), ipv4util.Uint32ToAddr(last))
+
+	return
+}
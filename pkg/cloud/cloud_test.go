@@ -0,0 +1,92 @@
+package cloud
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/imarsman/iptools/pkg/util"
+	"github.com/matryer/is"
+)
+
+func TestProvider(t *testing.T) {
+	is := is.New(t)
+
+	prefix := netip.MustParsePrefix("10.0.1.0/24")
+	var tests = []struct {
+		provider string
+		usable   int64
+		first    string
+		last     string
+	}{
+		{"aws", 251, "10.0.1.4", "10.0.1.254"},
+		{"azure", 251, "10.0.1.4", "10.0.1.254"},
+		{"gcp", 252, "10.0.1.2", "10.0.1.253"},
+	}
+	for _, test := range tests {
+		provider, err := ProviderByName(test.provider)
+		is.NoErr(err)
+		is.Equal(provider.UsableHosts(prefix), test.usable)
+		r, err := provider.UsableRange(prefix)
+		is.NoErr(err)
+		t.Log(test.provider, r.String(), provider.ReservedAddrs(prefix))
+		is.Equal(r.First().String(), test.first)
+		is.Equal(r.Last().String(), test.last)
+		is.Equal(len(provider.ReservedAddrs(prefix)), int(int64(256)-test.usable))
+	}
+
+	gcp, _ := ProviderByName("GCP")
+	reserved := gcp.ReservedAddrs(prefix)
+	is.Equal(reserved[2], ReservedAddr{Addr: netip.MustParseAddr("10.0.1.254"), Purpose: "reserved for future use"})
+
+	aws, _ := ProviderByName("aws")
+	err := aws.CheckSubnet(netip.MustParsePrefix("10.0.0.0/29"))
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+	is.True(aws.CheckSubnet(netip.MustParsePrefix("10.0.0.0/15")) != nil)
+	is.NoErr(aws.CheckSubnet(netip.MustParsePrefix("10.0.0.0/28")))
+	is.True(errors.Is(aws.CheckNetwork(netip.MustParsePrefix("2001:db8::/56")), util.ErrWrongFamily))
+
+	_, err = ProviderByName("oracle")
+	is.True(err != nil)
+}
+
+func TestPlan(t *testing.T) {
+	is := is.New(t)
+
+	aws, _ := ProviderByName("aws")
+	plan, err := NewPlan(aws, netip.MustParsePrefix("10.0.0.0/16"), ZoneNames(3), nil)
+	is.NoErr(err)
+	is.Equal(len(plan.Subnets), 9)
+	for _, subnet := range plan.Subnets {
+		t.Log(subnet.Tier, subnet.Zone, subnet.Prefix, subnet.UsableHosts, subnet.Usable.String())
+	}
+	// twelve units round up to sixteen /20s with the private subnets taking two each
+	is.Equal(plan.Subnets[0], PlanSubnet{
+		Tier:        "public",
+		Zone:        "az1",
+		Prefix:      netip.MustParsePrefix("10.0.96.0/20"),
+		UsableHosts: 4091,
+		Usable:      plan.Subnets[0].Usable,
+	})
+	is.Equal(plan.Subnets[3].Prefix.String(), "10.0.0.0/19")
+	is.Equal(plan.Subnets[3].UsableHosts, int64(8187))
+	is.Equal(plan.Subnets[3].Usable.First().String(), "10.0.0.4")
+	is.Equal(plan.Subnets[8].Prefix.String(), "10.0.176.0/20")
+	is.Equal(len(plan.Spare), 1)
+	is.Equal(plan.Spare[0].String(), "10.0.192.0/18")
+
+	tier, err := ParseTier("private:4")
+	is.NoErr(err)
+	is.Equal(tier, Tier{Name: "private", Weight: 4})
+	_, err = ParseTier("private:3")
+	is.True(err != nil)
+
+	// subnets smaller than the provider allows
+	_, err = NewPlan(aws, netip.MustParsePrefix("10.0.0.0/26"), ZoneNames(3), nil)
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+
+	_, err = NewPlan(aws, netip.MustParsePrefix("10.0.0.0/16"), ZoneNames(2), []Tier{{"app", 1}, {"app", 1}})
+	is.True(err != nil)
+}
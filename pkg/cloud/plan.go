@@ -0,0 +1,179 @@
+package cloud
+
+import (
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// Tier a kind of subnet such as public or private that every zone gets one of
+// The weight is the size of the tier's subnets relative to the others and is a power of
+// two, so a private tier of weight 2 gets subnets twice the size of a public tier of 1.
+type Tier struct {
+	Name   string
+	Weight int
+}
+
+// DefaultTiers public and database subnets with private subnets twice their size
+var DefaultTiers = []Tier{
+	{Name: "public", Weight: 1},
+	{Name: "private", Weight: 2},
+	{Name: "database", Weight: 1},
+}
+
+// ParseTier parse a tier as a name with an optional weight such as private:2
+func ParseTier(value string) (tier Tier, err error) {
+	name, weightStr, found := strings.Cut(strings.TrimSpace(value), ":")
+	tier = Tier{Name: strings.TrimSpace(name), Weight: 1}
+	if tier.Name == "" {
+		err = fmt.Errorf("tier %q has no name", value)
+		return
+	}
+	if found {
+		tier.Weight, err = strconv.Atoi(strings.TrimSpace(weightStr))
+		if err != nil {
+			err = fmt.Errorf("tier %q has an invalid weight", value)
+			return
+		}
+	}
+	if tier.Weight < 1 || bits.OnesCount(uint(tier.Weight)) != 1 {
+		err = fmt.Errorf("tier %q weight must be a power of two", value)
+	}
+
+	return
+}
+
+// PlanSubnet a subnet for a tier in a zone
+type PlanSubnet struct {
+	Tier        string
+	Zone        string
+	Prefix      netip.Prefix
+	UsableHosts int64
+	Usable      ipv4subnet.Range
+}
+
+// Plan subnets for each tier in each zone of a VPC or VNet
+type Plan struct {
+	Provider *Provider
+	Network  netip.Prefix
+	Subnets  []PlanSubnet
+	// Spare the space left after the subnets are allocated
+	Spare []netip.Prefix
+}
+
+// NewPlan split a network across zones into a subnet for each tier in each zone
+// The network is split into equal units, enough for every tier's weight in every zone,
+// and each subnet is given its tier's weight in units. Subnets are allocated largest
+// first so they stay aligned and are listed by tier and zone.
+func NewPlan(provider *Provider, network netip.Prefix, zones []string, tiers []Tier) (plan *Plan, err error) {
+	if len(zones) == 0 {
+		err = fmt.Errorf("no zones to plan for")
+		return
+	}
+	if len(tiers) == 0 {
+		tiers = DefaultTiers
+	}
+	network = util.UnmapPrefix(network)
+	err = provider.CheckNetwork(network)
+	if err != nil {
+		return
+	}
+	network = network.Masked()
+
+	units := 0
+	seen := make(map[string]bool)
+	for _, tier := range tiers {
+		if seen[tier.Name] {
+			err = fmt.Errorf("tier %s is listed more than once", tier.Name)
+			return
+		}
+		seen[tier.Name] = true
+		if tier.Weight < 1 || bits.OnesCount(uint(tier.Weight)) != 1 {
+			err = fmt.Errorf("tier %s weight %d is not a power of two", tier.Name, tier.Weight)
+			return
+		}
+		units += tier.Weight * len(zones)
+	}
+	// the bits to add to the network prefix to get a unit
+	unitBits := bits.Len(uint(units - 1))
+
+	order := make([]int, len(tiers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return tiers[order[i]].Weight > tiers[order[j]].Weight
+	})
+
+	plan = &Plan{Provider: provider, Network: network}
+	next := uint64(ipv4util.AddrToUint32(network.Addr()))
+	for _, i := range order {
+		tier := tiers[i]
+		subnetBits := network.Bits() + unitBits - bits.Len(uint(tier.Weight-1))
+		if subnetBits > 32 {
+			err = fmt.Errorf("%s is too small for %d units", network, units)
+			return
+		}
+		for _, zone := range zones {
+			prefix := netip.PrefixFrom(ipv4util.Uint32ToAddr(uint32(next)), subnetBits)
+			err = provider.CheckSubnet(prefix)
+			if err != nil {
+				err = fmt.Errorf("%s subnet for %s: %w", tier.Name, zone, err)
+				return
+			}
+			var usable ipv4subnet.Range
+			usable, err = provider.UsableRange(prefix)
+			if err != nil {
+				return
+			}
+			plan.Subnets = append(plan.Subnets, PlanSubnet{
+				Tier:        tier.Name,
+				Zone:        zone,
+				Prefix:      prefix,
+				UsableHosts: provider.UsableHosts(prefix),
+				Usable:      usable,
+			})
+			next += uint64(1) << (32 - subnetBits)
+		}
+	}
+
+	end := uint64(ipv4util.AddrToUint32(network.Addr())) + uint64(1)<<(32-network.Bits())
+	if next < end {
+		spare := ipv4subnet.NewRange(ipv4util.Uint32ToAddr(uint32(next)), ipv4util.Uint32ToAddr(uint32(end-1)))
+		plan.Spare = spare.Prefixes()
+	}
+
+	tierIndex := make(map[string]int)
+	for i, tier := range tiers {
+		tierIndex[tier.Name] = i
+	}
+	zoneIndex := make(map[string]int)
+	for i, zone := range zones {
+		zoneIndex[zone] = i
+	}
+	sort.SliceStable(plan.Subnets, func(i, j int) bool {
+		a, b := plan.Subnets[i], plan.Subnets[j]
+		if tierIndex[a.Tier] != tierIndex[b.Tier] {
+			return tierIndex[a.Tier] < tierIndex[b.Tier]
+		}
+		return zoneIndex[a.Zone] < zoneIndex[b.Zone]
+	})
+
+	return
+}
+
+// ZoneNames get names az1, az2 and so on for a number of zones
+func ZoneNames(count int) (zones []string) {
+	for i := 1; i <= count; i++ {
+		zones = append(zones, fmt.Sprintf("az%d", i))
+	}
+
+	return
+}
@@ -0,0 +1,154 @@
+package dhcp
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/imarsman/iptools/pkg/ipset"
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+const (
+	// GatewayFirst the gateway is the first usable address
+	GatewayFirst = "first"
+	// GatewayLast the gateway is the last usable address
+	GatewayLast = "last"
+	// GatewayNone there is no gateway
+	GatewayNone = "none"
+)
+
+const (
+	// ISC ISC dhcpd config
+	ISC = "isc"
+	// Kea Kea JSON config
+	Kea = "kea"
+	// Dnsmasq dnsmasq config
+	Dnsmasq = "dnsmasq"
+)
+
+// Formats the config formats that can be written
+var Formats = []string{ISC, Kea, Dnsmasq}
+
+// DefaultLeaseTime the lease time used if none is set
+const DefaultLeaseTime = time.Hour
+
+// Options the choices made for a scope that do not come from the subnet
+type Options struct {
+	// Gateway first, last or none
+	Gateway string
+	// Exclude ranges kept out of the pools such as those for static addresses
+	Exclude []ipv4subnet.Range
+	DNS     []netip.Addr
+	// LeaseTime the lease time, rounded down to whole seconds
+	LeaseTime time.Duration
+}
+
+// Scope a subnet and the addresses a DHCP server gives out in it
+// Mask and Broadcast are only set for IPV4. An IPV6 scope keeps its gateway out of the
+// pools but does not send it as DHCPV6 has no router option and routers are found
+// from router advertisements.
+type Scope struct {
+	Prefix    netip.Prefix
+	Mask      netip.Addr
+	Broadcast netip.Addr
+	Gateway   netip.Addr
+	Pools     []ipv4subnet.Range
+	DNS       []netip.Addr
+	// LeaseTime the lease time in seconds
+	LeaseTime int
+}
+
+// NewScope get the scope for a subnet
+// The pools are the usable addresses of the subnet without the gateway and excluded
+// ranges. For IPV6 the usable addresses leave out the subnet-router anycast address
+// that is the first address in the prefix.
+func NewScope(prefix netip.Prefix, options Options) (scope *Scope, err error) {
+	prefix = util.UnmapPrefix(prefix)
+	prefix = prefix.Masked()
+	if options.Gateway == "" {
+		options.Gateway = GatewayFirst
+	}
+	if options.LeaseTime == 0 {
+		options.LeaseTime = DefaultLeaseTime
+	}
+	if options.LeaseTime < time.Second {
+		err = fmt.Errorf("lease time %s is less than a second", options.LeaseTime)
+		return
+	}
+	scope = &Scope{Prefix: prefix, DNS: options.DNS, LeaseTime: int(options.LeaseTime / time.Second)}
+
+	var usable ipv4subnet.Range
+	if prefix.Addr().Is4() {
+		if prefix.Bits() >= 31 {
+			err = fmt.Errorf("%w: %s is too small to serve with DHCP", util.ErrInvalidPrefix, prefix)
+			return
+		}
+		var s *ipv4subnet.Subnet
+		s, err = ipv4subnet.NewFromPrefix(prefix.String())
+		if err != nil {
+			return
+		}
+		usable, err = s.UsableIPRange()
+		if err != nil {
+			return
+		}
+		scope.Mask = s.SubnetMask()
+		scope.Broadcast = s.BroadcastAddr()
+	} else {
+		if prefix.Bits() >= 127 {
+			err = fmt.Errorf("%w: %s is too small to serve with DHCPV6", util.ErrInvalidPrefix, prefix)
+			return
+		}
+		r := ipv4subnet.NewRangeFromPrefix(prefix)
+		usable = ipv4subnet.NewRange(r.First().Next(), r.Last())
+	}
+
+	switch options.Gateway {
+	case GatewayFirst:
+		scope.Gateway = usable.First()
+	case GatewayLast:
+		scope.Gateway = usable.Last()
+	case GatewayNone:
+	default:
+		err = fmt.Errorf("unknown gateway position %q, expected %s, %s or %s", options.Gateway, GatewayFirst, GatewayLast, GatewayNone)
+		return
+	}
+
+	for _, dns := range options.DNS {
+		if dns.Unmap().BitLen() != prefix.Addr().BitLen() {
+			err = fmt.Errorf("%w: DNS server %s is not the same family as %s", util.ErrWrongFamily, dns, prefix)
+			return
+		}
+	}
+
+	set := ipset.New()
+	set.AddRange(usable)
+	if scope.Gateway.IsValid() {
+		set.RemoveRange(ipv4subnet.NewRange(scope.Gateway, scope.Gateway))
+	}
+	for _, r := range options.Exclude {
+		if !prefix.Contains(r.First()) || !prefix.Contains(r.Last()) {
+			err = fmt.Errorf("%w: excluded range %s is not in %s", util.ErrInvalidPrefix, r.String(), prefix)
+			return
+		}
+		set.RemoveRange(r)
+	}
+	scope.Pools = set.Ranges()
+	if len(scope.Pools) == 0 {
+		err = fmt.Errorf("no addresses are left in %s for a pool", prefix)
+	}
+
+	return
+}
+
+// Is6 is the scope for an IPV6 prefix
+func (s *Scope) Is6() bool {
+	return s.Prefix.Addr().Is6()
+}
+
+// PreferredLifetime the DHCPV6 preferred lifetime in seconds, three quarters of the lease time
+func (s *Scope) PreferredLifetime() int {
+	return s.LeaseTime * 3 / 4
+}
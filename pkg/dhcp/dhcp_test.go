@@ -0,0 +1,134 @@
+package dhcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+	"github.com/matryer/is"
+)
+
+func TestScope(t *testing.T) {
+	is := is.New(t)
+
+	scope, err := NewScope(netip.MustParsePrefix("192.168.1.0/24"), Options{
+		Exclude: []ipv4subnet.Range{
+			ipv4subnet.NewRange(netip.MustParseAddr("192.168.1.2"), netip.MustParseAddr("192.168.1.19")),
+		},
+		DNS: []netip.Addr{netip.MustParseAddr("192.168.1.1"), netip.MustParseAddr("9.9.9.9")},
+	})
+	is.NoErr(err)
+	is.Equal(scope.Gateway, netip.MustParseAddr("192.168.1.1"))
+	is.Equal(scope.Mask, netip.MustParseAddr("255.255.255.0"))
+	is.Equal(scope.Broadcast, netip.MustParseAddr("192.168.1.255"))
+	is.Equal(len(scope.Pools), 1)
+	is.Equal(scope.Pools[0].String(), "192.168.1.20-192.168.1.254")
+	is.Equal(scope.LeaseTime, 3600)
+
+	scope, err = NewScope(netip.MustParsePrefix("10.0.0.0/29"), Options{Gateway: GatewayLast, LeaseTime: 12 * time.Hour})
+	is.NoErr(err)
+	is.Equal(scope.Gateway, netip.MustParseAddr("10.0.0.6"))
+	is.Equal(scope.Pools[0].String(), "10.0.0.1-10.0.0.5")
+	is.Equal(scope.LeaseTime, 43200)
+
+	// a static range in the middle splits the pool
+	scope, err = NewScope(netip.MustParsePrefix("2001:db8:1::/120"), Options{
+		Exclude: []ipv4subnet.Range{
+			ipv4subnet.NewRange(netip.MustParseAddr("2001:db8:1::80"), netip.MustParseAddr("2001:db8:1::8f")),
+		},
+	})
+	is.NoErr(err)
+	is.Equal(scope.Gateway, netip.MustParseAddr("2001:db8:1::1"))
+	is.Equal(len(scope.Pools), 2)
+	is.Equal(scope.Pools[0].String(), "2001:db8:1::2-2001:db8:1::7f")
+	is.Equal(scope.Pools[1].String(), "2001:db8:1::90-2001:db8:1::ff")
+
+	_, err = NewScope(netip.MustParsePrefix("192.168.1.0/24"), Options{DNS: []netip.Addr{netip.MustParseAddr("2001:db8::53")}})
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrWrongFamily))
+
+	_, err = NewScope(netip.MustParsePrefix("192.168.1.0/24"), Options{
+		Exclude: []ipv4subnet.Range{
+			ipv4subnet.NewRange(netip.MustParseAddr("192.168.2.1"), netip.MustParseAddr("192.168.2.9")),
+		},
+	})
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+
+	_, err = NewScope(netip.MustParsePrefix("192.168.1.0/31"), Options{})
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+
+	_, err = NewScope(netip.MustParsePrefix("192.168.1.0/24"), Options{Gateway: "middle"})
+	t.Log(err)
+	is.True(err != nil)
+}
+
+func TestWrite(t *testing.T) {
+	is := is.New(t)
+
+	scope, err := NewScope(netip.MustParsePrefix("192.168.1.0/24"), Options{
+		DNS: []netip.Addr{netip.MustParseAddr("192.168.1.1")},
+	})
+	is.NoErr(err)
+
+	buf := new(bytes.Buffer)
+	is.NoErr(Write(buf, ISC, scope))
+	t.Log("\n" + buf.String())
+	is.Equal(buf.String(), "subnet 192.168.1.0 netmask 255.255.255.0 {\n"+
+		"  range 192.168.1.2 192.168.1.254;\n"+
+		"  option routers 192.168.1.1;\n"+
+		"  option subnet-mask 255.255.255.0;\n"+
+		"  option broadcast-address 192.168.1.255;\n"+
+		"  option domain-name-servers 192.168.1.1;\n"+
+		"  default-lease-time 3600;\n"+
+		"  max-lease-time 7200;\n"+
+		"}\n")
+
+	buf.Reset()
+	is.NoErr(Write(buf, Dnsmasq, scope))
+	t.Log("\n" + buf.String())
+	is.Equal(buf.String(), "dhcp-range=192.168.1.2,192.168.1.254,255.255.255.0,192.168.1.255,3600\n"+
+		"dhcp-option=option:router,192.168.1.1\n"+
+		"dhcp-option=option:dns-server,192.168.1.1\n")
+
+	buf.Reset()
+	is.NoErr(Write(buf, Kea, scope))
+	t.Log("\n" + buf.String())
+	var config map[string]map[string]any
+	is.NoErr(json.Unmarshal(buf.Bytes(), &config))
+	is.True(config["Dhcp4"] != nil)
+
+	scope, err = NewScope(netip.MustParsePrefix("2001:db8:1::/64"), Options{
+		DNS: []netip.Addr{netip.MustParseAddr("2001:db8:1::53")},
+	})
+	is.NoErr(err)
+
+	buf.Reset()
+	is.NoErr(Write(buf, Kea, scope))
+	t.Log("\n" + buf.String())
+	config = nil
+	is.NoErr(json.Unmarshal(buf.Bytes(), &config))
+	is.Equal(config["Dhcp6"]["preferred-lifetime"], float64(2700))
+	is.True(strings.Contains(buf.String(), `"pool": "2001:db8:1::2 - 2001:db8:1:0:ffff:ffff:ffff:ffff"`))
+	is.True(strings.Contains(buf.String(), `"name": "dns-servers"`))
+
+	buf.Reset()
+	is.NoErr(Write(buf, ISC, scope))
+	t.Log("\n" + buf.String())
+	is.True(strings.HasPrefix(buf.String(), "subnet6 2001:db8:1::/64 {\n  range6 2001:db8:1::2 2001:db8:1:0:ffff:ffff:ffff:ffff;\n"))
+
+	buf.Reset()
+	is.NoErr(Write(buf, Dnsmasq, scope))
+	t.Log("\n" + buf.String())
+	is.Equal(buf.String(), "dhcp-range=2001:db8:1::2,2001:db8:1:0:ffff:ffff:ffff:ffff,64,3600\n"+
+		"dhcp-option=option6:dns-server,[2001:db8:1::53]\n")
+
+	is.True(Write(buf, "windows", scope) != nil)
+}
@@ -0,0 +1,177 @@
+package dhcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// Write write a scope as config for a DHCP server
+func Write(w io.Writer, format string, scope *Scope) (err error) {
+	switch format {
+	case ISC:
+		_, err = io.WriteString(w, scope.ISC())
+	case Dnsmasq:
+		_, err = io.WriteString(w, scope.Dnsmasq())
+	case Kea:
+		var text string
+		text, err = scope.Kea()
+		if err != nil {
+			return
+		}
+		_, err = io.WriteString(w, text)
+	default:
+		err = fmt.Errorf("unknown format %s, expected one of %v", format, Formats)
+	}
+
+	return
+}
+
+// joinAddrs join addresses with a separator
+func joinAddrs(addrs []netip.Addr, sep string) string {
+	values := []string{}
+	for _, addr := range addrs {
+		values = append(values, addr.String())
+	}
+
+	return strings.Join(values, sep)
+}
+
+// ISC get a subnet or subnet6 declaration for ISC dhcpd
+func (s *Scope) ISC() string {
+	var b strings.Builder
+	if s.Is6() {
+		fmt.Fprintf(&b, "subnet6 %s {\n", s.Prefix)
+		for _, pool := range s.Pools {
+			fmt.Fprintf(&b, "  range6 %s %s;\n", pool.First(), pool.Last())
+		}
+		if len(s.DNS) > 0 {
+			fmt.Fprintf(&b, "  option dhcp6.name-servers %s;\n", joinAddrs(s.DNS, ", "))
+		}
+		fmt.Fprintf(&b, "  default-lease-time %d;\n", s.LeaseTime)
+		fmt.Fprintf(&b, "  preferred-lifetime %d;\n", s.PreferredLifetime())
+	} else {
+		fmt.Fprintf(&b, "subnet %s netmask %s {\n", s.Prefix.Addr(), s.Mask)
+		for _, pool := range s.Pools {
+			fmt.Fprintf(&b, "  range %s %s;\n", pool.First(), pool.Last())
+		}
+		if s.Gateway.IsValid() {
+			fmt.Fprintf(&b, "  option routers %s;\n", s.Gateway)
+		}
+		fmt.Fprintf(&b, "  option subnet-mask %s;\n", s.Mask)
+		fmt.Fprintf(&b, "  option broadcast-address %s;\n", s.Broadcast)
+		if len(s.DNS) > 0 {
+			fmt.Fprintf(&b, "  option domain-name-servers %s;\n", joinAddrs(s.DNS, ", "))
+		}
+		fmt.Fprintf(&b, "  default-lease-time %d;\n", s.LeaseTime)
+		fmt.Fprintf(&b, "  max-lease-time %d;\n", s.LeaseTime*2)
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Dnsmasq get dhcp-range and dhcp-option lines for dnsmasq
+func (s *Scope) Dnsmasq() string {
+	var b strings.Builder
+	if s.Is6() {
+		for _, pool := range s.Pools {
+			fmt.Fprintf(&b, "dhcp-range=%s,%s,%d,%d\n", pool.First(), pool.Last(), s.Prefix.Bits(), s.LeaseTime)
+		}
+		if len(s.DNS) > 0 {
+			dns := []string{}
+			for _, addr := range s.DNS {
+				dns = append(dns, "["+addr.String()+"]")
+			}
+			fmt.Fprintf(&b, "dhcp-option=option6:dns-server,%s\n", strings.Join(dns, ","))
+		}
+	} else {
+		for _, pool := range s.Pools {
+			fmt.Fprintf(&b, "dhcp-range=%s,%s,%s,%s,%d\n", pool.First(), pool.Last(), s.Mask, s.Broadcast, s.LeaseTime)
+		}
+		if s.Gateway.IsValid() {
+			fmt.Fprintf(&b, "dhcp-option=option:router,%s\n", s.Gateway)
+		}
+		if len(s.DNS) > 0 {
+			fmt.Fprintf(&b, "dhcp-option=option:dns-server,%s\n", joinAddrs(s.DNS, ","))
+		}
+	}
+
+	return b.String()
+}
+
+// keaPool a pool in a Kea subnet
+type keaPool struct {
+	Pool string `json:"pool"`
+}
+
+// keaOption an option sent to clients in a Kea subnet
+type keaOption struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// keaSubnet a subnet4 or subnet6 entry in Kea config
+type keaSubnet struct {
+	ID         int         `json:"id"`
+	Subnet     string      `json:"subnet"`
+	Pools      []keaPool   `json:"pools"`
+	OptionData []keaOption `json:"option-data,omitempty"`
+}
+
+// keaDhcp4 the Dhcp4 section of Kea config
+type keaDhcp4 struct {
+	ValidLifetime int         `json:"valid-lifetime"`
+	MaxLifetime   int         `json:"max-valid-lifetime"`
+	Subnet4       []keaSubnet `json:"subnet4"`
+}
+
+// keaDhcp6 the Dhcp6 section of Kea config
+type keaDhcp6 struct {
+	ValidLifetime     int         `json:"valid-lifetime"`
+	PreferredLifetime int         `json:"preferred-lifetime"`
+	Subnet6           []keaSubnet `json:"subnet6"`
+}
+
+// Kea get Kea DHCPv4 or DHCPv6 config with the scope as its only subnet
+func (s *Scope) Kea() (text string, err error) {
+	subnet := keaSubnet{ID: 1, Subnet: s.Prefix.String()}
+	for _, pool := range s.Pools {
+		subnet.Pools = append(subnet.Pools, keaPool{Pool: fmt.Sprintf("%s - %s", pool.First(), pool.Last())})
+	}
+
+	var config any
+	if s.Is6() {
+		if len(s.DNS) > 0 {
+			subnet.OptionData = append(subnet.OptionData, keaOption{Name: "dns-servers", Data: joinAddrs(s.DNS, ", ")})
+		}
+		config = map[string]keaDhcp6{"Dhcp6": {
+			ValidLifetime:     s.LeaseTime,
+			PreferredLifetime: s.PreferredLifetime(),
+			Subnet6:           []keaSubnet{subnet},
+		}}
+	} else {
+		if s.Gateway.IsValid() {
+			subnet.OptionData = append(subnet.OptionData, keaOption{Name: "routers", Data: s.Gateway.String()})
+		}
+		subnet.OptionData = append(subnet.OptionData, keaOption{Name: "broadcast-address", Data: s.Broadcast.String()})
+		if len(s.DNS) > 0 {
+			subnet.OptionData = append(subnet.OptionData, keaOption{Name: "domain-name-servers", Data: joinAddrs(s.DNS, ", ")})
+		}
+		config = map[string]keaDhcp4{"Dhcp4": {
+			ValidLifetime: s.LeaseTime,
+			MaxLifetime:   s.LeaseTime * 2,
+			Subnet4:       []keaSubnet{subnet},
+		}}
+	}
+
+	bytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return
+	}
+	text = string(bytes) + "\n"
+
+	return
+}
@@ -0,0 +1,223 @@
+package dnszone
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/ipv6"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// MaxTemplateAddresses the most addresses a hostname template will name
+// Larger blocks are normally delegated as smaller zones.
+const MaxTemplateAddresses = 1 << 16
+
+// SOA the start of authority values for a zone
+// The first name server is the primary and all of them get NS records.
+type SOA struct {
+	NameServers []string
+	Contact     string
+	Serial      uint32
+	TTL         int
+	Refresh     int
+	Retry       int
+	Expire      int
+	Minimum     int
+}
+
+// DefaultSOA get SOA values with example.net servers and a serial for today
+func DefaultSOA() SOA {
+	return SOA{
+		NameServers: []string{"ns1.example.net."},
+		Contact:     "hostmaster.example.net.",
+		Serial:      DateSerial(time.Now()),
+		TTL:         3600,
+		Refresh:     3600,
+		Retry:       900,
+		Expire:      604800,
+		Minimum:     3600,
+	}
+}
+
+// DateSerial get a serial in the usual YYYYMMDDnn form for the first change on a day
+func DateSerial(t time.Time) uint32 {
+	return uint32(t.Year()*1000000 + int(t.Month())*10000 + t.Day()*100 + 1)
+}
+
+// Record a resource record with a name relative to the zone origin
+type Record struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// Zone a reverse zone and its records
+// A zone without an SOA is a set of records to add to a zone kept elsewhere.
+type Zone struct {
+	Origin  string
+	Comment string
+	SOA     *SOA
+	Records []Record
+}
+
+// Write write the zone in BIND master file format
+func (z *Zone) Write(writer io.Writer) (err error) {
+	w := bufio.NewWriter(writer)
+	if z.Comment != "" {
+		fmt.Fprintf(w, "; %s\n", z.Comment)
+	}
+	fmt.Fprintf(w, "$ORIGIN %s\n", z.Origin)
+	if z.SOA != nil {
+		soa := z.SOA
+		primary := "."
+		if len(soa.NameServers) > 0 {
+			primary = soa.NameServers[0]
+		}
+		fmt.Fprintf(w, "$TTL %d\n", soa.TTL)
+		fmt.Fprintf(w, "@\tIN\tSOA\t%s %s (\n", primary, soa.Contact)
+		fmt.Fprintf(w, "\t\t\t%d ; serial\n", soa.Serial)
+		fmt.Fprintf(w, "\t\t\t%d ; refresh\n", soa.Refresh)
+		fmt.Fprintf(w, "\t\t\t%d ; retry\n", soa.Retry)
+		fmt.Fprintf(w, "\t\t\t%d ; expire\n", soa.Expire)
+		fmt.Fprintf(w, "\t\t\t%d ) ; minimum\n", soa.Minimum)
+		for _, ns := range soa.NameServers {
+			fmt.Fprintf(w, "@\tIN\tNS\t%s\n", ns)
+		}
+	}
+	for _, record := range z.Records {
+		fmt.Fprintf(w, "%s\tIN\t%s\t%s\n", record.Name, record.Type, record.Value)
+	}
+
+	return w.Flush()
+}
+
+// FileName get a file name for the zone made from its origin
+// RFC 2317 origins hold a slash which is replaced by a dash.
+func (z *Zone) FileName() string {
+	return strings.ReplaceAll(strings.TrimSuffix(z.Origin, "."), "/", "-") + ".zone"
+}
+
+// Fqdn make a name absolute by adding the trailing dot if it is missing
+func Fqdn(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
+
+// placeholderRE matches placeholders such as {a} in a hostname template
+var placeholderRE = regexp.MustCompile(`\{[^}]*\}`)
+
+// TemplateNames get a hostname for each address in a prefix from a template
+// IPV4 templates can use {a}, {b}, {c} and {d} for the octets, IPV6 templates {h1}
+// to {h8} for the groups and any template can use {ip} for the address with dots
+// and colons changed to dashes. As with IPAM the network and broadcast addresses
+// of IPV4 subnets other than /31 and /32 are left out.
+func TemplateNames(prefix netip.Prefix, template string) (names map[netip.Addr]string, err error) {
+	if !prefix.IsValid() {
+		err = fmt.Errorf("%w: no prefix", util.ErrInvalidPrefix)
+		return
+	}
+	prefix = util.UnmapPrefix(prefix)
+	prefix = prefix.Masked()
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		err = fmt.Errorf("%s has more than %d addresses to name from a template", prefix, MaxTemplateAddresses)
+		return
+	}
+	for _, placeholder := range placeholderRE.FindAllString(template, -1) {
+		_, err = placeholderValue(prefix.Addr(), placeholder)
+		if err != nil {
+			return
+		}
+	}
+
+	names = make(map[netip.Addr]string)
+	addr := prefix.Addr()
+	for i := 0; i < 1<<hostBits; i++ {
+		if !(addr.Is4() && hostBits > 1 && (i == 0 || i == 1<<hostBits-1)) {
+			names[addr] = Fqdn(placeholderRE.ReplaceAllStringFunc(template, func(placeholder string) string {
+				value, _ := placeholderValue(addr, placeholder)
+				return value
+			}))
+		}
+		addr = addr.Next()
+	}
+
+	return
+}
+
+// placeholderValue get the value of a template placeholder for an address
+func placeholderValue(addr netip.Addr, placeholder string) (value string, err error) {
+	name := strings.Trim(placeholder, "{}")
+	if name == "ip" {
+		value = strings.NewReplacer(".", "-", ":", "-").Replace(addr.String())
+		return
+	}
+	if addr.Is4() && len(name) == 1 && name[0] >= 'a' && name[0] <= 'd' {
+		value = fmt.Sprintf("%d", addr.As4()[name[0]-'a'])
+		return
+	}
+	if addr.Is6() && len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '8' {
+		bytes := addr.As16()
+		i := int(name[1]-'1') * 2
+		value = fmt.Sprintf("%x", uint16(bytes[i])<<8|uint16(bytes[i+1]))
+		return
+	}
+	err = fmt.Errorf("unknown template placeholder %s for %s", placeholder, addr)
+
+	return
+}
+
+// NamedAddresses get the names for single addresses from a list of named prefixes
+// This is used to build a zone from an inventory of address to name pairs.
+func NamedAddresses(prefixes []ipv4subnet.NamedPrefix) (names map[netip.Addr]string, err error) {
+	names = make(map[netip.Addr]string)
+	for _, p := range prefixes {
+		if p.Prefix.Bits() != p.Prefix.Addr().BitLen() {
+			err = fmt.Errorf("%s is not a single address", p.Prefix)
+			return
+		}
+		if p.Name == "" {
+			err = fmt.Errorf("%s has no name", p.Prefix.Addr())
+			return
+		}
+		names[p.Prefix.Addr().Unmap()] = Fqdn(p.Name)
+	}
+
+	return
+}
+
+// sortedAddrs get the addresses in a prefix that have names in address order
+func sortedAddrs(prefix netip.Prefix, names map[netip.Addr]string) (addrs []netip.Addr) {
+	for addr := range names {
+		if prefix.Contains(addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].Less(addrs[j])
+	})
+
+	return
+}
+
+// FromArpa get the address or prefix for a full or partial in-addr.arpa or ip6.arpa name
+func FromArpa(name string) (prefix netip.Prefix, err error) {
+	fqdn := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if fqdn == "ip6.arpa" || strings.HasSuffix(fqdn, ".ip6.arpa") {
+		return ipv6.FromArpa(name)
+	}
+
+	return ipv4util.FromArpa(name)
+}
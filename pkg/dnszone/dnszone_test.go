@@ -0,0 +1,206 @@
+package dnszone
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/ipv6"
+	"github.com/imarsman/iptools/pkg/util"
+	"github.com/matryer/is"
+)
+
+func TestTemplateNames(t *testing.T) {
+	is := is.New(t)
+
+	names, err := TemplateNames(netip.MustParsePrefix("192.0.2.64/30"), "host-{a}-{b}-{c}-{d}.example.net")
+	is.NoErr(err)
+	// network and broadcast addresses are left out
+	is.Equal(len(names), 2)
+	is.Equal(names[netip.MustParseAddr("192.0.2.65")], "host-192-0-2-65.example.net.")
+	is.Equal(names[netip.MustParseAddr("192.0.2.66")], "host-192-0-2-66.example.net.")
+
+	names, err = TemplateNames(netip.MustParsePrefix("192.0.2.64/31"), "{ip}.example.net.")
+	is.NoErr(err)
+	is.Equal(len(names), 2)
+	is.Equal(names[netip.MustParseAddr("192.0.2.64")], "192-0-2-64.example.net.")
+
+	_, err = TemplateNames(netip.MustParsePrefix("192.0.2.0/24"), "host-{e}.example.net")
+	is.True(err != nil)
+	t.Log(err)
+	_, err = TemplateNames(netip.MustParsePrefix("10.0.0.0/15"), "host-{d}.example.net")
+	is.True(err != nil)
+	t.Log(err)
+}
+
+func TestIP4Zones(t *testing.T) {
+	is := is.New(t)
+
+	prefixes, err := IP4ZonePrefixes(netip.MustParsePrefix("10.1.16.0/20"))
+	is.NoErr(err)
+	is.Equal(len(prefixes), 16)
+	is.Equal(prefixes[15].String(), "10.1.31.0/24")
+
+	prefixes, err = IP4ZonePrefixes(netip.MustParsePrefix("240.0.0.0/4"))
+	is.NoErr(err)
+	is.Equal(len(prefixes), 16)
+	is.Equal(prefixes[15].String(), "255.0.0.0/8")
+
+	_, err = IP4ZonePrefixes(netip.MustParsePrefix("2001:db8::/32"))
+	is.True(err != nil)
+
+	soa := DefaultSOA()
+	soa.Serial = DateSerial(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC))
+	is.Equal(soa.Serial, uint32(2024030901))
+
+	names, err := NamedAddresses([]ipv4subnet.NamedPrefix{
+		{Name: "router.example.net", Prefix: netip.MustParsePrefix("10.1.0.1/32")},
+		{Name: "printer.example.net.", Prefix: netip.MustParsePrefix("10.1.2.20/32")},
+		{Name: "elsewhere.example.net", Prefix: netip.MustParsePrefix("10.2.0.1/32")},
+	})
+	is.NoErr(err)
+	zones, err := IP4Zones(netip.MustParsePrefix("10.1.0.0/16"), names, soa)
+	is.NoErr(err)
+	is.Equal(len(zones), 1)
+	is.Equal(zones[0].Origin, "1.10.in-addr.arpa.")
+	is.Equal(zones[0].Records, []Record{
+		{Name: "1.0", Type: "PTR", Value: "router.example.net."},
+		{Name: "20.2", Type: "PTR", Value: "printer.example.net."},
+	})
+
+	buf := new(bytes.Buffer)
+	is.NoErr(zones[0].Write(buf))
+	t.Log("\n" + buf.String())
+	is.True(strings.Contains(buf.String(), "$ORIGIN 1.10.in-addr.arpa.\n"))
+	is.True(strings.Contains(buf.String(), "2024030901 ; serial"))
+	is.True(strings.Contains(buf.String(), "@\tIN\tNS\tns1.example.net.\n"))
+	is.True(strings.Contains(buf.String(), "20.2\tIN\tPTR\tprinter.example.net.\n"))
+
+	_, err = NamedAddresses([]ipv4subnet.NamedPrefix{{Name: "net", Prefix: netip.MustParsePrefix("10.1.0.0/24")}})
+	is.True(err != nil)
+}
+
+func TestIP4Classless(t *testing.T) {
+	is := is.New(t)
+
+	prefix := netip.MustParsePrefix("192.0.2.64/26")
+	names, err := TemplateNames(prefix, "host-{d}.example.net")
+	is.NoErr(err)
+	zones, err := IP4Zones(prefix, names, DefaultSOA())
+	is.NoErr(err)
+	is.Equal(len(zones), 1)
+	is.Equal(zones[0].Origin, "64/26.2.0.192.in-addr.arpa.")
+	is.Equal(zones[0].FileName(), "64-26.2.0.192.in-addr.arpa.zone")
+	is.Equal(len(zones[0].Records), 62)
+	is.Equal(zones[0].Records[0], Record{Name: "65", Type: "PTR", Value: "host-65.example.net."})
+
+	delegation, err := IP4Delegation(prefix, []string{"ns1.example.net.", "ns2.example.net."})
+	is.NoErr(err)
+	is.Equal(delegation.Origin, "2.0.192.in-addr.arpa.")
+	is.True(delegation.SOA == nil)
+	is.Equal(len(delegation.Records), 66)
+	is.Equal(delegation.Records[1], Record{Name: "64/26", Type: "NS", Value: "ns2.example.net."})
+	is.Equal(delegation.Records[2], Record{Name: "64", Type: "CNAME", Value: "64.64/26.2.0.192.in-addr.arpa."})
+	is.Equal(delegation.Records[65].Name, "127")
+
+	_, err = IP4Delegation(netip.MustParsePrefix("192.0.2.0/24"), nil)
+	is.True(err != nil)
+}
+
+func TestIP6Zones(t *testing.T) {
+	is := is.New(t)
+
+	prefixes, err := IP6ZonePrefixes(netip.MustParsePrefix("2001:db8:abcd::/48"))
+	is.NoErr(err)
+	is.Equal(len(prefixes), 1)
+
+	prefixes, err = IP6ZonePrefixes(netip.MustParsePrefix("2001:db8:abcc::/46"))
+	is.NoErr(err)
+	is.Equal(len(prefixes), 4)
+	for i, want := range []string{"2001:db8:abcc::/48", "2001:db8:abcd::/48", "2001:db8:abce::/48", "2001:db8:abcf::/48"} {
+		is.Equal(prefixes[i].String(), want)
+	}
+
+	prefixes, err = IP6ZonePrefixes(netip.MustParsePrefix("2001:db8::/29"))
+	is.NoErr(err)
+	is.Equal(len(prefixes), 8)
+	is.Equal(prefixes[7].String(), "2001:dbf::/32")
+
+	_, err = IP6ZonePrefixes(netip.MustParsePrefix("192.0.2.0/24"))
+	is.True(err != nil)
+
+	names, err := TemplateNames(netip.MustParsePrefix("2001:db8:abcd:12::/126"), "host-{h4}-{h8}.example.net")
+	is.NoErr(err)
+	// IPV6 has no broadcast address so every address is named
+	is.Equal(len(names), 4)
+	is.Equal(names[netip.MustParseAddr("2001:db8:abcd:12::3")], "host-12-3.example.net.")
+
+	names[netip.MustParseAddr("2001:db8:abce::1")] = "outside.example.net."
+	zones, err := IP6Zones(netip.MustParsePrefix("2001:db8:abcd::/48"), names, DefaultSOA())
+	is.NoErr(err)
+	is.Equal(len(zones), 1)
+	is.Equal(zones[0].Origin, "d.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa.")
+	is.Equal(len(zones[0].Records), 4)
+	is.Equal(zones[0].Records[1], Record{Name: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.1.0.0", Type: "PTR", Value: "host-12-1.example.net."})
+
+	zones, err = IP6Zones(netip.MustParsePrefix("2001:db8:abcd:12::1/128"), names, DefaultSOA())
+	is.NoErr(err)
+	is.Equal(zones[0].Records[0].Name, "@")
+}
+
+func TestFromArpa(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		name   string
+		prefix string
+	}{
+		{"3.2.1.in-addr.arpa", "1.2.3.0/24"},
+		{"4.3.2.1.in-addr.arpa.", "1.2.3.4/32"},
+		{"10.IN-ADDR.ARPA", "10.0.0.0/8"},
+		{"in-addr.arpa", "0.0.0.0/0"},
+		{"64/26.2.0.192.in-addr.arpa", "192.0.2.64/26"},
+		{"65.64-26.2.0.192.in-addr.arpa", "192.0.2.65/32"},
+		{"d.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa", "2001:db8:abcd::/48"},
+		{"8.b.d.0.1.0.0.2.ip6.arpa.", "2001:db8::/32"},
+		{"c.b.a.8.b.d.0.1.0.0.2.ip6.arpa", "2001:db8:abc0::/44"},
+		{"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.B.D.0.1.0.0.2.IP6.ARPA", "2001:db8::1/128"},
+	}
+	for _, test := range tests {
+		prefix, err := FromArpa(test.name)
+		t.Log(test.name, prefix, err)
+		is.NoErr(err)
+		is.Equal(prefix.String(), test.prefix)
+	}
+
+	// names round trip
+	addr := netip.MustParseAddr("2001:db8:abcd:12::1")
+	prefix, err := FromArpa(ipv6.Arpa(addr))
+	is.NoErr(err)
+	is.Equal(prefix.Addr(), addr)
+	addr = netip.MustParseAddr("192.0.2.1")
+	prefix, err = FromArpa(ipv4util.Arpa(addr))
+	is.NoErr(err)
+	is.Equal(prefix.Addr(), addr)
+
+	for _, name := range []string{
+		"example.com",
+		"256.1.in-addr.arpa",
+		"01.1.in-addr.arpa",
+		"5.4.3.2.1.in-addr.arpa",
+		"1.64/26.2.0.192.in-addr.arpa",
+		"65/26.2.0.192.in-addr.arpa",
+		"64/20.2.0.192.in-addr.arpa",
+		"g.8.b.d.0.1.0.0.2.ip6.arpa",
+		"10.8.b.d.0.1.0.0.2.ip6.arpa",
+	} {
+		_, err := FromArpa(name)
+		t.Log(err)
+		is.True(errors.Is(err, util.ErrInvalidPrefix))
+	}
+}
@@ -0,0 +1,131 @@
+package dnszone
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// ip4Origin get the in-addr.arpa origin for an octet aligned IPV4 prefix
+func ip4Origin(prefix netip.Prefix) string {
+	octets := prefix.Addr().As4()
+	labels := []string{}
+	for i := prefix.Bits()/8 - 1; i >= 0; i-- {
+		labels = append(labels, fmt.Sprintf("%d", octets[i]))
+	}
+	labels = append(labels, "in-addr.arpa.")
+
+	return strings.Join(labels, ".")
+}
+
+// ip4ClasslessOrigin get the RFC 2317 origin for a prefix longer than /24
+// This is the first address and bits as a label under the /24 zone, such as
+// 64/26.2.0.192.in-addr.arpa.
+func ip4ClasslessOrigin(prefix netip.Prefix) string {
+	parent := netip.PrefixFrom(prefix.Addr(), 24).Masked()
+
+	return fmt.Sprintf("%d/%d.%s", prefix.Addr().As4()[3], prefix.Bits(), ip4Origin(parent))
+}
+
+// IP4ZonePrefixes get the prefixes that reverse zones are needed for to cover an IPV4 prefix
+// Zones are delegated on octet boundaries so a prefix such as a /20 needs a zone for
+// each of its /24s. Prefixes longer than /24 get an RFC 2317 classless zone.
+func IP4ZonePrefixes(prefix netip.Prefix) (prefixes []netip.Prefix, err error) {
+	prefix, err = ip4Prefix(prefix)
+	if err != nil {
+		return
+	}
+	if prefix.Bits() >= 24 || prefix.Bits()%8 == 0 {
+		prefixes = append(prefixes, prefix)
+		return
+	}
+	bits := prefix.Bits()/8*8 + 8
+	for addr := prefix.Addr(); prefix.Contains(addr); {
+		zonePrefix := netip.PrefixFrom(addr, bits)
+		prefixes = append(prefixes, zonePrefix)
+		// the last zone wraps to 0.0.0.0 at the end of the address space
+		addr = ipv4util.Uint32ToAddr(ipv4util.AddrToUint32(addr) + 1<<(32-bits))
+	}
+
+	return
+}
+
+// IP4Zones get the reverse zones with PTR records for the named addresses in an IPV4 prefix
+func IP4Zones(prefix netip.Prefix, names map[netip.Addr]string, soa SOA) (zones []*Zone, err error) {
+	prefixes, err := IP4ZonePrefixes(prefix)
+	if err != nil {
+		return
+	}
+	for _, zonePrefix := range prefixes {
+		zone := &Zone{SOA: &soa}
+		// labels for addresses in the zone are the octets below the origin
+		labels := zonePrefix.Bits() / 8
+		if zonePrefix.Bits() > 24 {
+			zone.Origin = ip4ClasslessOrigin(zonePrefix)
+			labels = 3
+		} else {
+			zone.Origin = ip4Origin(zonePrefix)
+		}
+		zone.Comment = fmt.Sprintf("reverse zone for %s", zonePrefix)
+		for _, addr := range sortedAddrs(zonePrefix, names) {
+			octets := addr.As4()
+			parts := []string{}
+			for i := 3; i >= labels; i-- {
+				parts = append(parts, fmt.Sprintf("%d", octets[i]))
+			}
+			zone.Records = append(zone.Records, Record{Name: strings.Join(parts, "."), Type: "PTR", Value: names[addr]})
+		}
+		zones = append(zones, zone)
+	}
+
+	return
+}
+
+// IP4Delegation get the RFC 2317 records for the /24 zone above a prefix longer than /24
+// The parent zone delegates the classless zone to the name servers and has a CNAME
+// for every address in the prefix pointing into it.
+func IP4Delegation(prefix netip.Prefix, nameServers []string) (zone *Zone, err error) {
+	prefix, err = ip4Prefix(prefix)
+	if err != nil {
+		return
+	}
+	if prefix.Bits() <= 24 {
+		err = fmt.Errorf("%s is on an octet boundary and needs no classless delegation", prefix)
+		return
+	}
+	parent := netip.PrefixFrom(prefix.Addr(), 24).Masked()
+	origin := ip4ClasslessOrigin(prefix)
+	label := strings.TrimSuffix(origin, "."+ip4Origin(parent))
+
+	zone = &Zone{
+		Origin:  ip4Origin(parent),
+		Comment: fmt.Sprintf("RFC 2317 delegation of %s to add to the zone for %s", prefix, parent),
+	}
+	for _, ns := range nameServers {
+		zone.Records = append(zone.Records, Record{Name: label, Type: "NS", Value: ns})
+	}
+	addr := prefix.Addr()
+	for prefix.Contains(addr) {
+		last := fmt.Sprintf("%d", addr.As4()[3])
+		zone.Records = append(zone.Records, Record{Name: last, Type: "CNAME", Value: last + "." + origin})
+		addr = addr.Next()
+	}
+
+	return
+}
+
+// ip4Prefix check that a prefix is IPV4, treating IPV4 mapped IPV6 prefixes as IPV4
+func ip4Prefix(prefix netip.Prefix) (netip.Prefix, error) {
+	if !prefix.IsValid() {
+		return prefix, fmt.Errorf("%w: no prefix", util.ErrInvalidPrefix)
+	}
+	prefix = util.UnmapPrefix(prefix)
+	if !prefix.Addr().Is4() {
+		return prefix, fmt.Errorf("%w: %s is not an IPV4 prefix", util.ErrWrongFamily, prefix)
+	}
+
+	return prefix.Masked(), nil
+}
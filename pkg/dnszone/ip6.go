@@ -0,0 +1,73 @@
+package dnszone
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/ipv6"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// IP6ZonePrefixes get the prefixes that ip6.arpa zones are needed for to cover an IPV6 prefix
+// Zones are delegated on nibble boundaries so a prefix such as a /46 needs a zone for
+// each of its four /48s.
+func IP6ZonePrefixes(prefix netip.Prefix) (prefixes []netip.Prefix, err error) {
+	if !prefix.IsValid() {
+		err = fmt.Errorf("%w: no prefix", util.ErrInvalidPrefix)
+		return
+	}
+	if !prefix.Addr().Is6() {
+		err = fmt.Errorf("%w: %s is not an IPV6 prefix", util.ErrWrongFamily, prefix)
+		return
+	}
+	prefix = prefix.Masked()
+	bits := (prefix.Bits() + 3) / 4 * 4
+	// a prefix is at most 3 bits short of a nibble so there are at most 8 zones
+	bytes := prefix.Addr().As16()
+	for i := 0; i < 1<<(bits-prefix.Bits()); i++ {
+		zoneBytes := bytes
+		// the zone number fills the bits between the prefix and the nibble boundary
+		for b := 0; b < bits-prefix.Bits(); b++ {
+			if i&(1<<b) != 0 {
+				bit := bits - 1 - b
+				zoneBytes[bit/8] |= 0x80 >> (bit % 8)
+			}
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(netip.AddrFrom16(zoneBytes), bits))
+	}
+
+	return
+}
+
+// IP6Zones get the ip6.arpa zones with PTR records for the named addresses in an IPV6 prefix
+func IP6Zones(prefix netip.Prefix, names map[netip.Addr]string, soa SOA) (zones []*Zone, err error) {
+	prefixes, err := IP6ZonePrefixes(prefix)
+	if err != nil {
+		return
+	}
+	for _, zonePrefix := range prefixes {
+		var origin string
+		origin, err = ipv6.PrefixArpa(zonePrefix)
+		if err != nil {
+			return
+		}
+		zone := &Zone{
+			Origin:  origin + ".",
+			Comment: fmt.Sprintf("reverse zone for %s", zonePrefix),
+			SOA:     &soa,
+		}
+		for _, addr := range sortedAddrs(zonePrefix, names) {
+			// the name is the nibbles of the address below the origin
+			name := strings.TrimSuffix(ipv6.Arpa(addr), origin)
+			name = strings.TrimSuffix(name, ".")
+			if name == "" {
+				name = "@"
+			}
+			zone.Records = append(zone.Records, Record{Name: name, Type: "PTR", Value: names[addr]})
+		}
+		zones = append(zones, zone)
+	}
+
+	return
+}
@@ -0,0 +1,305 @@
+package export
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/imarsman/iptools/pkg/ipsubnet"
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+const (
+	// Permit the action for prefixes that are allowed
+	Permit = "permit"
+	// Deny the action for prefixes that are blocked
+	Deny = "deny"
+)
+
+// Target a kind of device config that prefixes can be exported as
+type Target struct {
+	Name        string
+	Description string
+	template    *template.Template
+}
+
+// targets the registered targets by name
+var targets = make(map[string]*Target)
+
+// descriptionRE matches the comment at the start of a template that describes it
+var descriptionRE = regexp.MustCompile(`^\{\{-?\s*/\*\s*(.*?)\s*\*/`)
+
+// funcs functions available to templates
+var funcs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"quote": func(value string) string { return fmt.Sprintf("%q", value) },
+	// verdict get the firewall verdict for an action
+	"verdict": func(action string) string {
+		if action == Deny {
+			return "drop"
+		}
+		return "accept"
+	},
+}
+
+var (
+	builtinsOnce sync.Once
+	builtinsErr  error
+)
+
+// loadBuiltins register the embedded templates the first time targets are needed
+func loadBuiltins() error {
+	builtinsOnce.Do(func() {
+		entries, err := fs.ReadDir(templateFS, "templates")
+		if err != nil {
+			builtinsErr = err
+			return
+		}
+		for _, entry := range entries {
+			data, err := fs.ReadFile(templateFS, path.Join("templates", entry.Name()))
+			if err != nil {
+				builtinsErr = err
+				return
+			}
+			_, err = register(strings.TrimSuffix(entry.Name(), ".tmpl"), string(data))
+			if err != nil {
+				builtinsErr = err
+				return
+			}
+		}
+	})
+
+	return builtinsErr
+}
+
+// Register add a target from a text/template, replacing any target with the same name
+// The template is run with a Data value. A comment at the start of the template such
+// as {{/* Cisco IOS static routes */}} is taken as its description.
+func Register(name, text string) (target *Target, err error) {
+	// load the built in targets first so they do not replace this one
+	err = loadBuiltins()
+	if err != nil {
+		return
+	}
+
+	return register(name, text)
+}
+
+// register add a target from a text/template without loading the built in targets
+func register(name, text string) (target *Target, err error) {
+	tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		err = fmt.Errorf("template for %s: %w", name, err)
+		return
+	}
+	target = &Target{Name: name, template: tmpl}
+	if match := descriptionRE.FindStringSubmatch(text); match != nil {
+		target.Description = match[1]
+	}
+	targets[name] = target
+
+	return
+}
+
+// RegisterFile add a target from a template file named for the file without its extension
+func RegisterFile(file string) (target *Target, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+	return Register(name, string(data))
+}
+
+// Targets get the registered targets sorted by name
+func Targets() (list []*Target, err error) {
+	err = loadBuiltins()
+	if err != nil {
+		return
+	}
+	for _, target := range targets {
+		list = append(list, target)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return
+}
+
+// Options values used by templates that do not come from the prefixes
+// Templates choose their own defaults for an empty NextHop or Interface, such as
+// Null0 for Cisco routes and discard for Junos routes.
+type Options struct {
+	// Name the name of the ACL, prefix list, set or chain
+	Name string
+	// Action permit or deny
+	Action string
+	// GE and LE the prefix length range for prefix lists, 0 if not set
+	GE int
+	LE int
+	// Seq and SeqStep the number of the first entry and the gap between entries
+	Seq     int
+	SeqStep int
+	// NextHop the next hop for routes
+	NextHop string
+	// Interface the interface name, numbered by entry for interface configs
+	Interface string
+}
+
+// DefaultOptions get options for a permit list named IPTOOLS numbered from 10 by 10
+func DefaultOptions() Options {
+	return Options{
+		Name:    "IPTOOLS",
+		Action:  Permit,
+		Seq:     10,
+		SeqStep: 10,
+	}
+}
+
+// Entry a prefix with the values device configs are written with
+type Entry struct {
+	// Index the position of the entry counting from 1
+	Index int
+	// Seq the sequence number of the entry
+	Seq       int
+	Name      string
+	Prefix    netip.Prefix
+	Bits      int
+	Network   netip.Addr
+	Mask      netip.Addr
+	Wildcard  netip.Addr
+	Broadcast netip.Addr
+	// Gateway the first usable address, as used for an interface address
+	Gateway netip.Addr
+}
+
+// Data the value templates are run with
+type Data struct {
+	Options
+	Entries []Entry
+}
+
+// NewData get the template data for a list of IPV4 prefixes
+func NewData(prefixes []ipv4subnet.NamedPrefix, options Options) (data Data, err error) {
+	if options.Action != Permit && options.Action != Deny {
+		err = fmt.Errorf("unknown action %q, expected %s or %s", options.Action, Permit, Deny)
+		return
+	}
+	if len(prefixes) == 0 {
+		err = fmt.Errorf("no prefixes to export")
+		return
+	}
+	data.Options = options
+	for i, p := range prefixes {
+		prefix := p.Prefix
+		prefix = util.UnmapPrefix(prefix)
+		if !prefix.Addr().Is4() {
+			err = fmt.Errorf("%w: %s is not an IPV4 prefix", util.ErrWrongFamily, p.Prefix)
+			return
+		}
+		err = checkLengthRange(prefix.Bits(), options.GE, options.LE)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", prefix, err)
+			return
+		}
+		var s *ipv4subnet.Subnet
+		s, err = ipv4subnet.NewFromPrefix(prefix.Masked().String())
+		if err != nil {
+			return
+		}
+		var usable ipv4subnet.Range
+		usable, err = s.UsableIPRange()
+		if err != nil {
+			return
+		}
+		data.Entries = append(data.Entries, Entry{
+			Index:     i + 1,
+			Seq:       options.Seq + i*options.SeqStep,
+			Name:      p.Name,
+			Prefix:    s.Prefix(),
+			Bits:      s.Prefix().Bits(),
+			Network:   s.NetworkAddr(),
+			Mask:      s.SubnetMask(),
+			Wildcard:  s.WildcardMask(),
+			Broadcast: s.BroadcastAddr(),
+			Gateway:   usable.First(),
+		})
+	}
+
+	return
+}
+
+// checkLengthRange check ge and le values against a prefix length as IOS does
+// Both must be longer than the prefix and le must not be shorter than ge.
+func checkLengthRange(bits, ge, le int) error {
+	if ge != 0 && (ge <= bits || ge > 32) {
+		return fmt.Errorf("ge %d must be longer than /%d and at most 32", ge, bits)
+	}
+	if le != 0 && (le <= bits || le < ge || le > 32) {
+		return fmt.Errorf("le %d must be longer than /%d, at least ge and at most 32", le, bits)
+	}
+
+	return nil
+}
+
+// Divide get the subnets with prefix length bits inside an IPV4 prefix
+// Unlike divide, which lists the networks of that size in the prefix's class block,
+// only subnets inside the prefix are given so config never covers addresses outside it.
+func Divide(prefix netip.Prefix, bits int) (prefixes []ipv4subnet.NamedPrefix, err error) {
+	s, err := ipsubnet.New(prefix)
+	if err != nil {
+		return
+	}
+	if !s.Is4() {
+		err = fmt.Errorf("%w: %s is not an IPV4 prefix", util.ErrWrongFamily, prefix)
+		return
+	}
+	subnets, err := s.SubnetsSeq(bits)
+	if err != nil {
+		return
+	}
+	for subnet := range subnets {
+		prefixes = append(prefixes, ipv4subnet.NamedPrefix{Prefix: subnet.Prefix()})
+	}
+
+	return
+}
+
+// Export write prefixes as config for a target
+func Export(w io.Writer, targetName string, prefixes []ipv4subnet.NamedPrefix, options Options) (err error) {
+	list, err := Targets()
+	if err != nil {
+		return
+	}
+	target, ok := targets[targetName]
+	if !ok {
+		names := []string{}
+		for _, t := range list {
+			names = append(names, t.Name)
+		}
+		err = fmt.Errorf("unknown target %s, expected one of %v", targetName, names)
+		return
+	}
+	data, err := NewData(prefixes, options)
+	if err != nil {
+		return
+	}
+
+	return target.template.Execute(w, data)
+}
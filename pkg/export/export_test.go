@@ -0,0 +1,136 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+	"github.com/matryer/is"
+)
+
+var testPrefixes = []ipv4subnet.NamedPrefix{
+	{Name: "users", Prefix: netip.MustParsePrefix("10.0.0.0/24")},
+	{Prefix: netip.MustParsePrefix("10.0.1.5/25")},
+}
+
+func TestExport(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		target  string
+		options func(*Options)
+		want    string
+	}{
+		{"cisco-acl", nil, "ip access-list standard IPTOOLS\n" +
+			" remark users\n" +
+			" 10 permit 10.0.0.0 0.0.0.255\n" +
+			" 20 permit 10.0.1.0 0.0.0.127\n"},
+		{"cisco-route", func(o *Options) { o.NextHop = "192.0.2.1" }, "ip route 10.0.0.0 255.255.255.0 192.0.2.1 name users\n" +
+			"ip route 10.0.1.0 255.255.255.128 192.0.2.1\n"},
+		{"cisco-prefix-list", func(o *Options) { o.GE, o.LE, o.Seq, o.SeqStep = 26, 28, 5, 5 },
+			"ip prefix-list IPTOOLS seq 5 permit 10.0.0.0/24 ge 26 le 28\n" +
+				"ip prefix-list IPTOOLS seq 10 permit 10.0.1.0/25 ge 26 le 28\n"},
+		{"cisco-interface", nil, "interface Vlan1\n description users\n ip address 10.0.0.1 255.255.255.0\n!\n" +
+			"interface Vlan2\n ip address 10.0.1.1 255.255.255.128\n!\n"},
+		{"junos-route-filter", func(o *Options) { o.Action, o.LE = Deny, 32 },
+			"set policy-options policy-statement IPTOOLS term 10 from route-filter 10.0.0.0/24 upto /32\n" +
+				"set policy-options policy-statement IPTOOLS term 10 then reject\n" +
+				"set policy-options policy-statement IPTOOLS term 20 from route-filter 10.0.1.0/25 upto /32\n" +
+				"set policy-options policy-statement IPTOOLS term 20 then reject\n"},
+		{"junos-interface", nil, "set interfaces irb unit 1 description \"users\"\n" +
+			"set interfaces irb unit 1 family inet address 10.0.0.1/24\n" +
+			"set interfaces irb unit 2 family inet address 10.0.1.1/25\n"},
+		{"nftables", nil, "table inet filter {\n\tset IPTOOLS {\n\t\ttype ipv4_addr\n\t\tflags interval\n" +
+			"\t\telements = {\n\t\t\t10.0.0.0/24,\n\t\t\t10.0.1.0/25\n\t\t}\n\t}\n}\n"},
+		{"iptables", func(o *Options) { o.Action = Deny }, "*filter\n:IPTOOLS - [0:0]\n" +
+			"-A IPTOOLS -s 10.0.0.0/24 -m comment --comment \"users\" -j DROP\n" +
+			"-A IPTOOLS -s 10.0.1.0/25 -j DROP\nCOMMIT\n"},
+	}
+	for _, test := range tests {
+		options := DefaultOptions()
+		if test.options != nil {
+			test.options(&options)
+		}
+		buf := new(bytes.Buffer)
+		err := Export(buf, test.target, testPrefixes, options)
+		is.NoErr(err)
+		t.Log("\n" + buf.String())
+		is.Equal(buf.String(), test.want)
+	}
+
+	// every embedded template parses and has a description
+	list, err := Targets()
+	is.NoErr(err)
+	is.Equal(len(list), 10)
+	for _, target := range list {
+		is.True(target.Description != "")
+	}
+
+	options := DefaultOptions()
+	options.GE = 24
+	err = Export(new(bytes.Buffer), "cisco-prefix-list", testPrefixes, options)
+	t.Log(err)
+	is.True(err != nil)
+
+	err = Export(new(bytes.Buffer), "cisco-acl",
+		[]ipv4subnet.NamedPrefix{{Prefix: netip.MustParsePrefix("2001:db8::/32")}}, DefaultOptions())
+	is.True(errors.Is(err, util.ErrWrongFamily))
+
+	err = Export(new(bytes.Buffer), "no-such-vendor", testPrefixes, DefaultOptions())
+	t.Log(err)
+	is.True(err != nil)
+}
+
+func TestDivide(t *testing.T) {
+	is := is.New(t)
+
+	// a parent that is not aligned to its class block is split only within itself
+	prefixes, err := Divide(netip.MustParsePrefix("10.32.0.64/26"), 28)
+	is.NoErr(err)
+	got := []string{}
+	for _, p := range prefixes {
+		got = append(got, p.Prefix.String())
+	}
+	t.Log(got)
+	is.Equal(got, []string{"10.32.0.64/28", "10.32.0.80/28", "10.32.0.96/28", "10.32.0.112/28"})
+
+	buf := new(bytes.Buffer)
+	options := DefaultOptions()
+	options.NextHop = "192.0.2.1"
+	is.NoErr(Export(buf, "cisco-route", prefixes, options))
+	is.Equal(buf.String(), "ip route 10.32.0.64 255.255.255.240 192.0.2.1\n"+
+		"ip route 10.32.0.80 255.255.255.240 192.0.2.1\n"+
+		"ip route 10.32.0.96 255.255.255.240 192.0.2.1\n"+
+		"ip route 10.32.0.112 255.255.255.240 192.0.2.1\n")
+
+	_, err = Divide(netip.MustParsePrefix("10.32.0.64/26"), 24)
+	is.True(errors.Is(err, util.ErrSplitToFewerBits))
+	_, err = Divide(netip.MustParsePrefix("2001:db8::/48"), 64)
+	is.True(errors.Is(err, util.ErrWrongFamily))
+}
+
+func TestRegisterFile(t *testing.T) {
+	is := is.New(t)
+
+	file := filepath.Join(t.TempDir(), "mikrotik.tmpl")
+	text := "{{- /* MikroTik address list */ -}}\n" +
+		"{{range .Entries}}/ip firewall address-list add list={{$.Name}} address={{.Prefix}}\n{{end -}}\n"
+	is.NoErr(os.WriteFile(file, []byte(text), 0o644))
+
+	target, err := RegisterFile(file)
+	is.NoErr(err)
+	is.Equal(target.Name, "mikrotik")
+	is.Equal(target.Description, "MikroTik address list")
+
+	buf := new(bytes.Buffer)
+	is.NoErr(Export(buf, "mikrotik", testPrefixes[:1], DefaultOptions()))
+	is.Equal(buf.String(), "/ip firewall address-list add list=IPTOOLS address=10.0.0.0/24\n")
+
+	_, err = Register("broken", "{{range .Entries}")
+	is.True(err != nil)
+}
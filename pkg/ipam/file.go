@@ -0,0 +1,116 @@
+package ipam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Init create an empty store file if there is none
+func Init(path string) (err error) {
+	return withLock(path, true, func() (err error) {
+		_, err = os.Stat(path)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+
+		return save(path, new(Store))
+	})
+}
+
+// View read the store while holding a shared lock on it
+func View(path string, fn func(*Store) error) (err error) {
+	return withLock(path, false, func() (err error) {
+		store, err := load(path)
+		if err != nil {
+			return
+		}
+
+		return fn(store)
+	})
+}
+
+// Update change the store while holding an exclusive lock on it
+// The store is only written if fn returns no error.
+func Update(path string, fn func(*Store) error) (err error) {
+	return withLock(path, true, func() (err error) {
+		store, err := load(path)
+		if err != nil {
+			return
+		}
+		err = fn(store)
+		if err != nil {
+			return
+		}
+
+		return save(path, store)
+	})
+}
+
+// withLock run fn holding a lock on a file next to the store
+// The store itself is replaced on every write so it can't hold the lock.
+func withLock(path string, exclusive bool, fn func() error) (err error) {
+	lockFile, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return
+	}
+	defer lockFile.Close()
+
+	err = lock(lockFile, exclusive)
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer unlock(lockFile)
+
+	return fn()
+}
+
+// load read a store file
+func load(path string) (store *Store, err error) {
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = fmt.Errorf("no IPAM store at %s, create one with ipam init", path)
+		return
+	}
+	if err != nil {
+		return
+	}
+	store = new(Store)
+	err = json.Unmarshal(bytes, store)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+
+	return
+}
+
+// save write a store file by writing a temporary file and renaming it over the old one
+func save(path string, store *Store) (err error) {
+	bytes, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(append(bytes, '\n'))
+	if err != nil {
+		file.Close()
+		return
+	}
+	err = file.Close()
+	if err != nil {
+		return
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,382 @@
+package ipam
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"sort"
+	"time"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// Store pools of IPV4 and IPV6 prefixes with their allocations and reservations
+type Store struct {
+	Pools []*Pool `json:"pools" yaml:"pools"`
+}
+
+// Pool a prefix that subnets are allocated from and addresses reserved in
+type Pool struct {
+	Name         string         `json:"name" yaml:"name"`
+	Prefix       netip.Prefix   `json:"prefix" yaml:"prefix"`
+	Allocations  []*Allocation  `json:"allocations,omitempty" yaml:"allocations,omitempty"`
+	Reservations []*Reservation `json:"reservations,omitempty" yaml:"reservations,omitempty"`
+}
+
+// Allocation a named subnet taken from a pool
+type Allocation struct {
+	Name    string       `json:"name" yaml:"name"`
+	Prefix  netip.Prefix `json:"prefix" yaml:"prefix"`
+	Created time.Time    `json:"created" yaml:"created"`
+}
+
+// Reservation a named address reserved in a pool or in one of its allocations
+type Reservation struct {
+	Name    string     `json:"name" yaml:"name"`
+	Addr    netip.Addr `json:"address" yaml:"address"`
+	Subnet  string     `json:"subnet,omitempty" yaml:"subnet,omitempty"`
+	Created time.Time  `json:"created" yaml:"created"`
+}
+
+// Subnet get the allocation as a named IPV4 subnet
+// IPV6 allocations have no Subnet type so they give ErrWrongFamily, use Prefix for them.
+func (a *Allocation) Subnet() (subnet *ipv4subnet.Subnet, err error) {
+	if !a.Prefix.Addr().Is4() {
+		err = fmt.Errorf("%w: allocation %s is %s, not IPV4", ipv4subnet.ErrWrongFamily, a.Name, a.Prefix)
+		return
+	}
+
+	return ipv4subnet.NewNamedFromPrefix(a.Prefix.String(), a.Name)
+}
+
+// AddPool add a pool that does not overlap any other pool
+func (s *Store) AddPool(name string, prefix netip.Prefix) (pool *Pool, err error) {
+	if name == "" {
+		err = fmt.Errorf("pool name is required")
+		return
+	}
+	if !prefix.IsValid() {
+		err = fmt.Errorf("%w: pool %s has no prefix", ipv4subnet.ErrInvalidPrefix, name)
+		return
+	}
+	prefix = util.UnmapPrefix(prefix).Masked()
+	for _, p := range s.Pools {
+		if p.Name == name {
+			err = fmt.Errorf("pool %s already exists", name)
+			return
+		}
+		if p.Prefix.Overlaps(prefix) {
+			err = fmt.Errorf("pool %s %s overlaps pool %s %s", name, prefix, p.Name, p.Prefix)
+			return
+		}
+	}
+	pool = &Pool{Name: name, Prefix: prefix}
+	s.Pools = append(s.Pools, pool)
+
+	return
+}
+
+// Pool get a pool by name
+func (s *Store) Pool(name string) (pool *Pool, err error) {
+	for _, p := range s.Pools {
+		if p.Name == name {
+			pool = p
+			return
+		}
+	}
+	err = fmt.Errorf("no pool named %s", name)
+
+	return
+}
+
+// Allocation get an allocation by name
+func (p *Pool) Allocation(name string) (allocation *Allocation, err error) {
+	for _, a := range p.Allocations {
+		if a.Name == name {
+			allocation = a
+			return
+		}
+	}
+	err = fmt.Errorf("no allocation named %s in pool %s", name, p.Name)
+
+	return
+}
+
+// Allocate take the first free subnet with prefix bits, or with bestFit the one from the smallest free block
+func (p *Pool) Allocate(name string, bits int, bestFit bool) (allocation *Allocation, err error) {
+	err = p.checkName(name)
+	if err != nil {
+		return
+	}
+	prefix, err := ipv4subnet.FindFreePrefix(p.Prefix, p.used(), bits, bestFit)
+	if err != nil {
+		err = fmt.Errorf("pool %s: %w", p.Name, err)
+		return
+	}
+
+	return p.addAllocation(name, prefix), nil
+}
+
+// AllocatePrefix take a given subnet if it is in the pool and free
+func (p *Pool) AllocatePrefix(name string, prefix netip.Prefix) (allocation *Allocation, err error) {
+	err = p.checkName(name)
+	if err != nil {
+		return
+	}
+	prefix = util.UnmapPrefix(prefix).Masked()
+	if prefix.Addr().BitLen() != p.Prefix.Addr().BitLen() {
+		err = fmt.Errorf("%w: %s is not in the same family as pool %s", ipv4subnet.ErrWrongFamily, prefix, p.Name)
+		return
+	}
+	if prefix.Bits() < p.Prefix.Bits() || !p.Prefix.Contains(prefix.Addr()) {
+		err = fmt.Errorf("%s is not inside pool %s %s", prefix, p.Name, p.Prefix)
+		return
+	}
+	for _, used := range p.used() {
+		if used.Overlaps(prefix) {
+			err = fmt.Errorf("%s overlaps %s already in use in pool %s", prefix, used, p.Name)
+			return
+		}
+	}
+
+	return p.addAllocation(name, prefix), nil
+}
+
+// Reserve reserve an address in the pool
+// An address inside an allocation is reserved in that allocation.
+func (p *Pool) Reserve(name string, addr netip.Addr) (reservation *Reservation, err error) {
+	err = p.checkName(name)
+	if err != nil {
+		return
+	}
+	addr = addr.Unmap()
+	if !p.Prefix.Contains(addr) {
+		err = fmt.Errorf("%s is not inside pool %s %s", addr, p.Name, p.Prefix)
+		return
+	}
+	scope, subnet := p.Prefix, ""
+	for _, a := range p.Allocations {
+		if a.Prefix.Contains(addr) {
+			scope, subnet = a.Prefix, a.Name
+			break
+		}
+	}
+	if !usable(scope, addr) {
+		err = fmt.Errorf("%s is not a usable address in %s", addr, scope)
+		return
+	}
+	for _, r := range p.Reservations {
+		if r.Addr == addr {
+			err = fmt.Errorf("%s is already reserved as %s in pool %s", addr, r.Name, p.Name)
+			return
+		}
+	}
+
+	return p.addReservation(name, addr, subnet), nil
+}
+
+// ReserveNext reserve the first free address in the pool or in a named allocation
+func (p *Pool) ReserveNext(name, subnet string) (reservation *Reservation, err error) {
+	err = p.checkName(name)
+	if err != nil {
+		return
+	}
+	scope := p.Prefix
+	// addresses in allocations are skipped when reserving in the pool itself
+	skip := []netip.Prefix{}
+	if subnet != "" {
+		var allocation *Allocation
+		allocation, err = p.Allocation(subnet)
+		if err != nil {
+			return
+		}
+		scope = allocation.Prefix
+	} else {
+		for _, a := range p.Allocations {
+			skip = append(skip, a.Prefix)
+		}
+	}
+	reserved := make(map[netip.Addr]bool)
+	for _, r := range p.Reservations {
+		reserved[r.Addr] = true
+	}
+
+	last := prefixLast(scope)
+	addr := scope.Addr()
+	for scope.Contains(addr) {
+		inAllocation := false
+		for _, prefix := range skip {
+			if prefix.Contains(addr) {
+				// jump past the whole allocation
+				inAllocation = true
+				if prefixLast(prefix) == last {
+					addr = netip.Addr{}
+				} else {
+					addr = prefixLast(prefix).Next()
+				}
+				break
+			}
+		}
+		if inAllocation {
+			continue
+		}
+		if usable(scope, addr) && !reserved[addr] {
+			return p.addReservation(name, addr, subnet), nil
+		}
+		if addr == last {
+			break
+		}
+		addr = addr.Next()
+	}
+	err = fmt.Errorf("no free address in %s", scope)
+
+	return
+}
+
+// Release release an allocation and the reservations in it or a single reservation by name
+func (p *Pool) Release(name string) (err error) {
+	for i, a := range p.Allocations {
+		if a.Name != name {
+			continue
+		}
+		p.Allocations = append(p.Allocations[:i], p.Allocations[i+1:]...)
+		reservations := []*Reservation{}
+		for _, r := range p.Reservations {
+			if r.Subnet != name {
+				reservations = append(reservations, r)
+			}
+		}
+		p.Reservations = reservations
+
+		return
+	}
+	for i, r := range p.Reservations {
+		if r.Name == name {
+			p.Reservations = append(p.Reservations[:i], p.Reservations[i+1:]...)
+			return
+		}
+	}
+	err = fmt.Errorf("no allocation or reservation named %s in pool %s", name, p.Name)
+
+	return
+}
+
+// ReleaseAddr release the reservation for an address
+func (p *Pool) ReleaseAddr(addr netip.Addr) (err error) {
+	addr = addr.Unmap()
+	for i, r := range p.Reservations {
+		if r.Addr == addr {
+			p.Reservations = append(p.Reservations[:i], p.Reservations[i+1:]...)
+			return
+		}
+	}
+	err = fmt.Errorf("%s is not reserved in pool %s", addr, p.Name)
+
+	return
+}
+
+// Free get the free space in the pool as maximal prefixes
+func (p *Pool) Free() (free []netip.Prefix, err error) {
+	return ipv4subnet.FreePrefixes(p.Prefix, p.used())
+}
+
+// Size get the number of addresses in the pool
+func (p *Pool) Size() *big.Int {
+	return blockSize(p.Prefix)
+}
+
+// Used get the number of addresses in the pool taken by allocations and reservations outside them
+func (p *Pool) Used() *big.Int {
+	used := new(big.Int)
+	for _, prefix := range p.used() {
+		used.Add(used, blockSize(prefix))
+	}
+
+	return used
+}
+
+// checkName make sure a name is set and not already used in the pool
+func (p *Pool) checkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("a name is required")
+	}
+	for _, a := range p.Allocations {
+		if a.Name == name {
+			return fmt.Errorf("%s is already allocated as %s in pool %s", name, a.Prefix, p.Name)
+		}
+	}
+	for _, r := range p.Reservations {
+		if r.Name == name {
+			return fmt.Errorf("%s is already reserved as %s in pool %s", name, r.Addr, p.Name)
+		}
+	}
+
+	return nil
+}
+
+// used get the prefixes taken by allocations and reservations outside of them
+func (p *Pool) used() (prefixes []netip.Prefix) {
+	for _, a := range p.Allocations {
+		prefixes = append(prefixes, a.Prefix)
+	}
+	for _, r := range p.Reservations {
+		if r.Subnet == "" {
+			prefixes = append(prefixes, netip.PrefixFrom(r.Addr, r.Addr.BitLen()))
+		}
+	}
+
+	return
+}
+
+// addAllocation add an allocation keeping allocations in address order
+func (p *Pool) addAllocation(name string, prefix netip.Prefix) *Allocation {
+	allocation := &Allocation{Name: name, Prefix: prefix, Created: time.Now().UTC()}
+	p.Allocations = append(p.Allocations, allocation)
+	sort.SliceStable(p.Allocations, func(i, j int) bool {
+		return p.Allocations[i].Prefix.Addr().Less(p.Allocations[j].Prefix.Addr())
+	})
+
+	return allocation
+}
+
+// addReservation add a reservation keeping reservations in address order
+func (p *Pool) addReservation(name string, addr netip.Addr, subnet string) *Reservation {
+	reservation := &Reservation{Name: name, Addr: addr, Subnet: subnet, Created: time.Now().UTC()}
+	p.Reservations = append(p.Reservations, reservation)
+	sort.SliceStable(p.Reservations, func(i, j int) bool {
+		return p.Reservations[i].Addr.Less(p.Reservations[j].Addr)
+	})
+
+	return reservation
+}
+
+// usable can an address in a prefix be handed out
+// The network and broadcast addresses of IPV4 subnets other than /31 and /32 are
+// not usable and neither is the subnet-router anycast address of IPV6 subnets
+// other than /127 and /128.
+func usable(prefix netip.Prefix, addr netip.Addr) bool {
+	if prefix.Bits() >= prefix.Addr().BitLen()-1 {
+		return true
+	}
+	if addr == prefix.Addr() {
+		return false
+	}
+	if addr.Is4() && addr == prefixLast(prefix) {
+		return false
+	}
+
+	return true
+}
+
+// prefixLast get the last address in a prefix
+func prefixLast(prefix netip.Prefix) netip.Addr {
+	r := ipv4subnet.NewRangeFromPrefix(prefix)
+
+	return r.Last()
+}
+
+// blockSize get the number of addresses in a prefix
+func blockSize(prefix netip.Prefix) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+}
@@ -0,0 +1,139 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/matryer/is"
+)
+
+func TestAllocate(t *testing.T) {
+	is := is.New(t)
+
+	store := new(Store)
+	pool, err := store.AddPool("lab", netip.MustParsePrefix("10.20.0.0/16"))
+	is.NoErr(err)
+	_, err = store.AddPool("lab2", netip.MustParsePrefix("10.20.128.0/17"))
+	is.True(err != nil)
+
+	web, err := pool.Allocate("web", 24, false)
+	is.NoErr(err)
+	is.Equal(web.Prefix.String(), "10.20.0.0/24")
+	db, err := pool.Allocate("db", 26, false)
+	is.NoErr(err)
+	is.Equal(db.Prefix.String(), "10.20.1.0/26")
+	_, err = pool.AllocatePrefix("mgmt", netip.MustParsePrefix("10.20.1.32/27"))
+	is.True(err != nil)
+	_, err = pool.Allocate("db", 26, false)
+	is.True(err != nil)
+	_, err = pool.Allocate("big", 15, false)
+	is.True(errors.Is(err, ipv4subnet.ErrSplitToFewerBits))
+
+	subnet, err := db.Subnet()
+	is.NoErr(err)
+	is.Equal(subnet.Name(), "db")
+	is.Equal(subnet.UsableHosts(), int64(62))
+
+	// addresses in an allocation skip the network address
+	r, err := pool.ReserveNext("db1", "db")
+	is.NoErr(err)
+	is.Equal(r.Addr.String(), "10.20.1.1")
+	r, err = pool.Reserve("db2", netip.MustParseAddr("10.20.1.2"))
+	is.NoErr(err)
+	is.Equal(r.Subnet, "db")
+	_, err = pool.Reserve("db3", netip.MustParseAddr("10.20.1.63"))
+	is.True(err != nil)
+
+	// addresses in the pool skip allocations
+	r, err = pool.ReserveNext("gateway", "")
+	is.NoErr(err)
+	is.Equal(r.Addr.String(), "10.20.1.64")
+	next, err := pool.Allocate("next", 26, false)
+	is.NoErr(err)
+	is.Equal(next.Prefix.String(), "10.20.1.128/26")
+
+	is.Equal(pool.Used().Int64(), int64(256+64+64+1))
+	is.NoErr(pool.Release("db"))
+	is.Equal(len(pool.Reservations), 1)
+	is.NoErr(pool.ReleaseAddr(netip.MustParseAddr("10.20.1.64")))
+	is.Equal(len(pool.Reservations), 0)
+	is.True(pool.Release("db") != nil)
+
+	free, err := pool.Free()
+	is.NoErr(err)
+	t.Log(free)
+	is.Equal(free[0].String(), "10.20.1.0/25")
+}
+
+func TestIP6Pool(t *testing.T) {
+	is := is.New(t)
+
+	store := new(Store)
+	pool, err := store.AddPool("site", netip.MustParsePrefix("2001:db8:100::/48"))
+	is.NoErr(err)
+
+	a, err := pool.Allocate("vlan10", 64, false)
+	is.NoErr(err)
+	is.Equal(a.Prefix.String(), "2001:db8:100::/64")
+	a, err = pool.Allocate("vlan20", 64, false)
+	is.NoErr(err)
+	is.Equal(a.Prefix.String(), "2001:db8:100:1::/64")
+	_, err = a.Subnet()
+	is.True(errors.Is(err, ipv4subnet.ErrWrongFamily))
+
+	r, err := pool.ReserveNext("router", "vlan20")
+	is.NoErr(err)
+	is.Equal(r.Addr.String(), "2001:db8:100:1::1")
+	_, err = pool.AllocatePrefix("v4", netip.MustParsePrefix("10.0.0.0/24"))
+	is.True(errors.Is(err, ipv4subnet.ErrWrongFamily))
+
+	is.Equal(pool.Size().String(), "1208925819614629174706176")
+}
+
+func TestStoreFile(t *testing.T) {
+	is := is.New(t)
+
+	path := filepath.Join(t.TempDir(), "ipam.json")
+	err := View(path, func(*Store) error { return nil })
+	is.True(err != nil)
+
+	is.NoErr(Init(path))
+	is.NoErr(Update(path, func(store *Store) (err error) {
+		_, err = store.AddPool("lab", netip.MustParsePrefix("10.0.0.0/16"))
+		return
+	}))
+	// running init again keeps the store
+	is.NoErr(Init(path))
+
+	// concurrent allocations all get distinct subnets
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := Update(path, func(store *Store) (err error) {
+				pool, err := store.Pool("lab")
+				if err != nil {
+					return
+				}
+				_, err = pool.Allocate(fmt.Sprintf("net%d", i), 24, false)
+				return
+			})
+			is.NoErr(err)
+		}(i)
+	}
+	wg.Wait()
+
+	is.NoErr(View(path, func(store *Store) error {
+		pool, err := store.Pool("lab")
+		is.NoErr(err)
+		is.Equal(len(pool.Allocations), 20)
+		is.Equal(pool.Allocations[19].Prefix.String(), "10.0.19.0/24")
+		return nil
+	}))
+}
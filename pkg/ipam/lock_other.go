@@ -0,0 +1,19 @@
+//go:build !unix && !windows
+
+package ipam
+
+import (
+	"errors"
+	"os"
+)
+
+// lock is not supported on this platform so the store is not used rather than
+// risk concurrent runs overwriting each other
+func lock(file *os.File, exclusive bool) error {
+	return errors.ErrUnsupported
+}
+
+// unlock is not supported on this platform
+func unlock(file *os.File) error {
+	return errors.ErrUnsupported
+}
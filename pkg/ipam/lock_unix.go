@@ -0,0 +1,23 @@
+//go:build unix
+
+package ipam
+
+import (
+	"os"
+	"syscall"
+)
+
+// lock take a shared or exclusive lock on a file, waiting until it is free
+func lock(file *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+
+	return syscall.Flock(int(file.Fd()), how)
+}
+
+// unlock release a lock on a file
+func unlock(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package ipam
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// lockfileExclusiveLock the LockFileEx flag for an exclusive rather than shared lock
+	lockfileExclusiveLock = 0x2
+	// maxDWORD the low and high halves of the byte range locked, covering the whole file
+	maxDWORD = 0xffffffff
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lock take a shared or exclusive lock on a file, waiting until it is free
+func lock(file *os.File, exclusive bool) error {
+	var flags uint32
+	if exclusive {
+		flags = lockfileExclusiveLock
+	}
+	overlapped := new(syscall.Overlapped)
+	r, _, err := procLockFileEx.Call(file.Fd(), uintptr(flags), 0, maxDWORD, maxDWORD,
+		uintptr(unsafe.Pointer(overlapped)))
+	if r == 0 {
+		return err
+	}
+
+	return nil
+}
+
+// unlock release a lock on a file
+func unlock(file *os.File) error {
+	overlapped := new(syscall.Overlapped)
+	r, _, err := procUnlockFileEx.Call(file.Fd(), 0, maxDWORD, maxDWORD,
+		uintptr(unsafe.Pointer(overlapped)))
+	if r == 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package ipset
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// IPSet a set of IPV4 and IPV6 addresses
+// The set is kept as a sorted list of ranges that neither overlap nor touch.
+type IPSet struct {
+	ranges []ipv4subnet.Range
+}
+
+// New get a new empty set
+func New() *IPSet {
+	return new(IPSet)
+}
+
+// Parse get a new set from a list of prefixes, addresses and first-last ranges
+func Parse(values ...string) (set *IPSet, err error) {
+	set = New()
+	for _, value := range values {
+		var r ipv4subnet.Range
+		r, err = parseRange(value)
+		if err != nil {
+			return
+		}
+		set.AddRange(r)
+	}
+
+	return
+}
+
+// parseRange get a range for a prefix, address or first-last range
+func parseRange(value string) (r ipv4subnet.Range, err error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "-") {
+		return ipv4subnet.ParseRange(value)
+	}
+	if strings.Contains(value, "/") {
+		var prefix netip.Prefix
+		prefix, err = netip.ParsePrefix(value)
+		if err != nil {
+			err = fmt.Errorf("%w: %v", ipv4subnet.ErrInvalidPrefix, err)
+			return
+		}
+		r = rangeForPrefix(prefix)
+
+		return
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		err = fmt.Errorf("%w: %q is not a prefix, address or range", ipv4subnet.ErrInvalidPrefix, value)
+		return
+	}
+	addr = addr.Unmap()
+	r = ipv4subnet.NewRange(addr, addr)
+
+	return
+}
+
+// rangeForPrefix get the range for a prefix treating IPV4 mapped IPV6 prefixes as IPV4
+func rangeForPrefix(prefix netip.Prefix) ipv4subnet.Range {
+	prefix = util.UnmapPrefix(prefix)
+
+	return ipv4subnet.NewRangeFromPrefix(prefix)
+}
+
+// overlaps do two ranges share any addresses
+func overlaps(a, b ipv4subnet.Range) bool {
+	if a.First().BitLen() != b.First().BitLen() {
+		return false
+	}
+
+	return a.First().Compare(b.Last()) <= 0 && b.First().Compare(a.Last()) <= 0
+}
+
+// Add add the addresses in a prefix to the set
+func (s *IPSet) Add(prefix netip.Prefix) {
+	if !prefix.IsValid() {
+		return
+	}
+	s.AddRange(rangeForPrefix(prefix))
+}
+
+// AddRange add the addresses in a range to the set
+func (s *IPSet) AddRange(r ipv4subnet.Range) {
+	s.ranges = ipv4subnet.MergeRanges(append(s.ranges, r))
+}
+
+// Remove remove the addresses in a prefix from the set
+func (s *IPSet) Remove(prefix netip.Prefix) {
+	if !prefix.IsValid() {
+		return
+	}
+	s.RemoveRange(rangeForPrefix(prefix))
+}
+
+// RemoveRange remove the addresses in a range from the set
+func (s *IPSet) RemoveRange(r ipv4subnet.Range) {
+	ranges := []ipv4subnet.Range{}
+	for _, current := range s.ranges {
+		if !overlaps(current, r) {
+			ranges = append(ranges, current)
+			continue
+		}
+		// keep whatever is left on either side of the removed range
+		if current.First().Less(r.First()) {
+			ranges = append(ranges, ipv4subnet.NewRange(current.First(), r.First().Prev()))
+		}
+		if r.Last().Less(current.Last()) {
+			ranges = append(ranges, ipv4subnet.NewRange(r.Last().Next(), current.Last()))
+		}
+	}
+	s.ranges = ranges
+}
+
+// Union get a new set with the addresses in either set
+func (s *IPSet) Union(other *IPSet) *IPSet {
+	ranges := append(s.Ranges(), other.ranges...)
+
+	return &IPSet{ranges: ipv4subnet.MergeRanges(ranges)}
+}
+
+// Intersect get a new set with the addresses in both sets
+func (s *IPSet) Intersect(other *IPSet) *IPSet {
+	ranges := []ipv4subnet.Range{}
+	for _, a := range s.ranges {
+		for _, b := range other.ranges {
+			if !overlaps(a, b) {
+				continue
+			}
+			first, last := a.First(), a.Last()
+			if first.Less(b.First()) {
+				first = b.First()
+			}
+			if b.Last().Less(last) {
+				last = b.Last()
+			}
+			ranges = append(ranges, ipv4subnet.NewRange(first, last))
+		}
+	}
+
+	return &IPSet{ranges: ipv4subnet.MergeRanges(ranges)}
+}
+
+// Difference get a new set with the addresses in the set that are not in other
+func (s *IPSet) Difference(other *IPSet) *IPSet {
+	set := &IPSet{ranges: s.Ranges()}
+	for _, r := range other.ranges {
+		set.RemoveRange(r)
+	}
+
+	return set
+}
+
+// Contains is the address in the set
+func (s *IPSet) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return s.ContainsRange(ipv4subnet.NewRange(addr, addr))
+}
+
+// ContainsPrefix are all addresses in the prefix in the set
+func (s *IPSet) ContainsPrefix(prefix netip.Prefix) bool {
+	return s.ContainsRange(rangeForPrefix(prefix))
+}
+
+// ContainsRange are all addresses in the range in the set
+func (s *IPSet) ContainsRange(r ipv4subnet.Range) bool {
+	for _, current := range s.ranges {
+		if overlaps(current, r) {
+			return current.First().Compare(r.First()) <= 0 && r.Last().Compare(current.Last()) <= 0
+		}
+	}
+
+	return false
+}
+
+// IsEmpty does the set have no addresses
+func (s *IPSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Ranges get the minimal list of ranges in the set
+func (s *IPSet) Ranges() (ranges []ipv4subnet.Range) {
+	ranges = make([]ipv4subnet.Range, len(s.ranges))
+	copy(ranges, s.ranges)
+
+	return
+}
+
+// Prefixes get the minimal list of prefixes in the set
+func (s *IPSet) Prefixes() (prefixes []netip.Prefix) {
+	for _, r := range s.ranges {
+		prefixes = append(prefixes, r.Prefixes()...)
+	}
+
+	return
+}
@@ -0,0 +1,58 @@
+package ipset
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func prefixStrings(set *IPSet) (list []string) {
+	for _, p := range set.Prefixes() {
+		list = append(list, p.String())
+	}
+	return
+}
+
+func TestDifference(t *testing.T) {
+	is := is.New(t)
+
+	base, err := Parse("10.0.0.0/8")
+	is.NoErr(err)
+	allocated, err := Parse("10.1.0.0/16", "10.2.0.0/15")
+	is.NoErr(err)
+
+	left := base.Difference(allocated)
+	t.Log(prefixStrings(left))
+	is.Equal(prefixStrings(left), []string{
+		"10.0.0.0/16", "10.4.0.0/14", "10.8.0.0/13", "10.16.0.0/12", "10.32.0.0/11", "10.64.0.0/10", "10.128.0.0/9",
+	})
+	is.True(!left.Contains(netip.MustParseAddr("10.3.4.5")))
+	is.True(left.Contains(netip.MustParseAddr("10.200.0.1")))
+	is.True(left.ContainsPrefix(netip.MustParsePrefix("10.4.0.0/15")))
+	is.True(!left.ContainsPrefix(netip.MustParsePrefix("10.0.0.0/15")))
+
+	// the base set is not changed
+	is.Equal(prefixStrings(base), []string{"10.0.0.0/8"})
+}
+
+func TestUnionIntersect(t *testing.T) {
+	is := is.New(t)
+
+	a, err := Parse("10.0.0.0/24", "10.0.2.0/24", "2001:db8::/48")
+	is.NoErr(err)
+	b, err := Parse("10.0.1.0/24", "10.0.2.128-10.0.3.255", "2001:db8:0:8000::/49")
+	is.NoErr(err)
+
+	is.Equal(prefixStrings(a.Union(b)), []string{"10.0.0.0/22", "2001:db8::/48"})
+	is.Equal(prefixStrings(a.Intersect(b)), []string{"10.0.2.128/25", "2001:db8:0:8000::/49"})
+	is.True(New().Intersect(a).IsEmpty())
+
+	set := New()
+	set.Add(netip.MustParsePrefix("192.168.0.0/24"))
+	set.Remove(netip.MustParsePrefix("192.168.0.0/25"))
+	set.Remove(netip.MustParsePrefix("192.168.0.255/32"))
+	ranges := set.Ranges()
+	is.Equal(len(ranges), 1)
+	is.Equal(ranges[0].String(), "192.168.0.128-192.168.0.254")
+}
@@ -0,0 +1,225 @@
+package ipsubnet
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"net/netip"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// Subnet an IPV4 or IPV6 subnet
+// Unlike ipv4subnet.Subnet, which lists the networks of a size in the subnet's class
+// block, a Subnet is only ever split within its own prefix. Addresses are worked out
+// with 128 bit integers and counts that can be larger than an int64 are big integers.
+type Subnet struct {
+	prefix netip.Prefix
+}
+
+// New get a subnet for a prefix
+// IPV4-mapped IPV6 prefixes are taken as IPV4.
+func New(prefix netip.Prefix) (subnet *Subnet, err error) {
+	if !prefix.IsValid() {
+		err = fmt.Errorf("%w: %s", util.ErrInvalidPrefix, prefix)
+		return
+	}
+	prefix = util.UnmapPrefix(prefix)
+	subnet = &Subnet{prefix: prefix.Masked()}
+
+	return
+}
+
+// Parse get a subnet for a prefix in any notation ipv4util.ParsePrefix accepts
+func Parse(value string) (subnet *Subnet, err error) {
+	prefix, err := ipv4util.ParsePrefix(value)
+	if err != nil {
+		return
+	}
+
+	return New(prefix)
+}
+
+// Prefix get the prefix for the subnet
+func (s *Subnet) Prefix() netip.Prefix {
+	return s.prefix
+}
+
+// String get the subnet in CIDR notation
+func (s *Subnet) String() string {
+	return s.prefix.String()
+}
+
+// Is4 is the subnet IPV4
+func (s *Subnet) Is4() bool {
+	return s.prefix.Addr().Is4()
+}
+
+// Is6 is the subnet IPV6
+func (s *Subnet) Is6() bool {
+	return s.prefix.Addr().Is6()
+}
+
+// BitLen the number of bits in an address of the subnet's family
+func (s *Subnet) BitLen() int {
+	return s.prefix.Addr().BitLen()
+}
+
+// HostBits the number of bits for hosts in the subnet
+func (s *Subnet) HostBits() int {
+	return s.BitLen() - s.prefix.Bits()
+}
+
+// First get the first address in the subnet
+func (s *Subnet) First() netip.Addr {
+	return s.prefix.Addr()
+}
+
+// Last get the last address in the subnet, the broadcast address for IPV4
+func (s *Subnet) Last() netip.Addr {
+	return u128FromAddr(s.First()).or(hostMask(s.HostBits())).addr(s.BitLen())
+}
+
+// Range get the range of addresses in the subnet
+func (s *Subnet) Range() ipv4subnet.Range {
+	return ipv4subnet.NewRange(s.First(), s.Last())
+}
+
+// UsableRange get the range of addresses usable for hosts
+// IPV4 subnets lose the network and broadcast addresses except for a /31 (RFC 3021) or
+// /32. IPV6 has no broadcast address so every address is usable.
+func (s *Subnet) UsableRange() ipv4subnet.Range {
+	if s.Is6() || s.HostBits() <= 1 {
+		return s.Range()
+	}
+
+	return ipv4subnet.NewRange(s.First().Next(), s.Last().Prev())
+}
+
+// Addresses the number of addresses in the subnet
+func (s *Subnet) Addresses() *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(s.HostBits()))
+}
+
+// UsableHosts the number of addresses in the usable range
+func (s *Subnet) UsableHosts() *big.Int {
+	count := s.Addresses()
+	if s.Is4() && s.HostBits() > 1 {
+		count.Sub(count, big.NewInt(2))
+	}
+
+	return count
+}
+
+// Contains is an address in the subnet
+func (s *Subnet) Contains(addr netip.Addr) bool {
+	return s.prefix.Contains(addr.Unmap())
+}
+
+// Networks the number of subnets with prefix length bits that the subnet splits into
+func (s *Subnet) Networks(bits int) (count *big.Int, err error) {
+	err = s.checkBits(bits)
+	if err != nil {
+		return
+	}
+	count = new(big.Int).Lsh(big.NewInt(1), uint(bits-s.prefix.Bits()))
+
+	return
+}
+
+// checkBits check that the subnet can be split into subnets with prefix length bits
+func (s *Subnet) checkBits(bits int) error {
+	if bits < s.prefix.Bits() {
+		return fmt.Errorf("%w: %s split to /%d", util.ErrSplitToFewerBits, s, bits)
+	}
+	if bits > s.BitLen() {
+		return fmt.Errorf("%w: /%d is longer than a %d bit address", util.ErrInvalidPrefix, bits, s.BitLen())
+	}
+
+	return nil
+}
+
+// AddrsSeq iterate over every address in the subnet
+func (s *Subnet) AddrsSeq() iter.Seq[netip.Addr] {
+	return rangeAddrs(s.Range())
+}
+
+// UsableAddrsSeq iterate over the addresses in the usable range
+func (s *Subnet) UsableAddrsSeq() iter.Seq[netip.Addr] {
+	return rangeAddrs(s.UsableRange())
+}
+
+// rangeAddrs iterate over the addresses in a range
+func rangeAddrs(r ipv4subnet.Range) iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		for addr := r.First(); addr.IsValid() && !r.Last().Less(addr); addr = addr.Next() {
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
+
+// RangesSeq iterate over the ranges of the subnets with prefix length bits
+func (s *Subnet) RangesSeq(bits int) (iter.Seq[ipv4subnet.Range], error) {
+	return s.RangesFromSeq(bits, new(big.Int))
+}
+
+// RangesFromSeq iterate over the ranges of the subnets with prefix length bits from an offset
+// The offset is the number of subnets to skip, so the first range of a /48 split into
+// /64s from offset 65535 is its last /64.
+func (s *Subnet) RangesFromSeq(bits int, offset *big.Int) (seq iter.Seq[ipv4subnet.Range], err error) {
+	count, err := s.Networks(bits)
+	if err != nil {
+		return
+	}
+	hostBits := s.BitLen() - bits
+	mask := hostMask(hostBits)
+	step := uint128{lo: 1}.lsh(hostBits)
+	last := u128FromAddr(s.Last())
+
+	seq = func(yield func(ipv4subnet.Range) bool) {
+		if offset.Sign() < 0 || offset.Cmp(count) >= 0 {
+			return
+		}
+		index, _ := u128FromBig(offset)
+		first := u128FromAddr(s.First()).add(index.lsh(hostBits))
+		for {
+			end := first.or(mask)
+			if !yield(ipv4subnet.NewRange(first.addr(s.BitLen()), end.addr(s.BitLen()))) {
+				return
+			}
+			if end.cmp(last) >= 0 {
+				return
+			}
+			first = first.add(step)
+		}
+	}
+
+	return
+}
+
+// SubnetsSeq iterate over the subnets with prefix length bits
+func (s *Subnet) SubnetsSeq(bits int) (iter.Seq[*Subnet], error) {
+	return s.SubnetsFromSeq(bits, new(big.Int))
+}
+
+// SubnetsFromSeq iterate over the subnets with prefix length bits from an offset
+func (s *Subnet) SubnetsFromSeq(bits int, offset *big.Int) (seq iter.Seq[*Subnet], err error) {
+	ranges, err := s.RangesFromSeq(bits, offset)
+	if err != nil {
+		return
+	}
+
+	seq = func(yield func(*Subnet) bool) {
+		for r := range ranges {
+			if !yield(&Subnet{prefix: netip.PrefixFrom(r.First(), bits)}) {
+				return
+			}
+		}
+	}
+
+	return
+}
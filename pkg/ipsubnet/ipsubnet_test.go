@@ -0,0 +1,126 @@
+package ipsubnet
+
+import (
+	"errors"
+	"math/big"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/imarsman/iptools/pkg/util"
+	"github.com/matryer/is"
+)
+
+func TestSubnet(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		prefix      string
+		first       string
+		last        string
+		usable      string
+		addresses   string
+		usableHosts string
+	}{
+		{"10.1.2.3/24", "10.1.2.0", "10.1.2.255", "10.1.2.1-10.1.2.254", "256", "254"},
+		{"10.1.2.0/31", "10.1.2.0", "10.1.2.1", "10.1.2.0-10.1.2.1", "2", "2"},
+		{"0.0.0.0/0", "0.0.0.0", "255.255.255.255", "0.0.0.1-255.255.255.254", "4294967296", "4294967294"},
+		{"::ffff:192.168.0.0/120", "192.168.0.0", "192.168.0.255", "192.168.0.1-192.168.0.254", "256", "254"},
+		{"2001:db8::/48", "2001:db8::", "2001:db8:0:ffff:ffff:ffff:ffff:ffff",
+			"2001:db8::-2001:db8:0:ffff:ffff:ffff:ffff:ffff", "1208925819614629174706176", "1208925819614629174706176"},
+		{"::/0", "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+			"::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "340282366920938463463374607431768211456", "340282366920938463463374607431768211456"},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.prefix)
+		is.NoErr(err)
+		t.Log(s, s.First(), s.Last(), s.Addresses())
+		is.Equal(s.First().String(), test.first)
+		is.Equal(s.Last().String(), test.last)
+		usable := s.UsableRange()
+		is.Equal(usable.String(), test.usable)
+		is.Equal(s.Addresses().String(), test.addresses)
+		is.Equal(s.UsableHosts().String(), test.usableHosts)
+	}
+
+	s, err := Parse("2001:db8::/126")
+	is.NoErr(err)
+	is.Equal(len(slices.Collect(s.AddrsSeq())), 4)
+	is.True(s.Contains(netip.MustParseAddr("2001:db8::3")))
+	is.True(!s.Contains(netip.MustParseAddr("2001:db8::4")))
+
+	s, err = Parse("192.168.1.0/30")
+	is.NoErr(err)
+	is.Equal(slices.Collect(s.UsableAddrsSeq()),
+		[]netip.Addr{netip.MustParseAddr("192.168.1.1"), netip.MustParseAddr("192.168.1.2")})
+}
+
+func TestSplit(t *testing.T) {
+	is := is.New(t)
+
+	s, err := Parse("2001:db8::/48")
+	is.NoErr(err)
+	count, err := s.Networks(64)
+	is.NoErr(err)
+	is.Equal(count.Int64(), int64(65536))
+
+	subnets, err := s.SubnetsSeq(64)
+	is.NoErr(err)
+	n := 0
+	var last *Subnet
+	for subnet := range subnets {
+		if n < 2 {
+			t.Log(subnet)
+		}
+		last = subnet
+		n++
+	}
+	is.Equal(n, 65536)
+	is.Equal(last.String(), "2001:db8:0:ffff::/64")
+
+	// from an offset without walking the subnets before it
+	ranges, err := s.RangesFromSeq(64, big.NewInt(65534))
+	is.NoErr(err)
+	got := []string{}
+	for r := range ranges {
+		got = append(got, r.String())
+	}
+	is.Equal(got, []string{
+		"2001:db8:0:fffe::-2001:db8:0:fffe:ffff:ffff:ffff:ffff",
+		"2001:db8:0:ffff::-2001:db8:0:ffff:ffff:ffff:ffff:ffff",
+	})
+
+	ranges, err = s.RangesFromSeq(64, big.NewInt(65536))
+	is.NoErr(err)
+	is.Equal(len(slices.Collect(ranges)), 0)
+
+	// a whole address space split in two
+	s, err = Parse("::/0")
+	is.NoErr(err)
+	subnets, err = s.SubnetsSeq(1)
+	is.NoErr(err)
+	got = []string{}
+	for subnet := range subnets {
+		got = append(got, subnet.String())
+	}
+	is.Equal(got, []string{"::/1", "8000::/1"})
+
+	// IPV4 is split within the prefix, not its class block
+	s, err = Parse("10.0.0.0/23")
+	is.NoErr(err)
+	subnets, err = s.SubnetsSeq(25)
+	is.NoErr(err)
+	got = []string{}
+	for subnet := range subnets {
+		got = append(got, subnet.String())
+	}
+	is.Equal(got, []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/25", "10.0.1.128/25"})
+
+	_, err = s.SubnetsSeq(22)
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrSplitToFewerBits))
+
+	_, err = s.SubnetsSeq(33)
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+}
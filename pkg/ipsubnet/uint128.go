@@ -0,0 +1,116 @@
+package ipsubnet
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+	"net/netip"
+)
+
+// uint128 an unsigned 128 bit integer for address arithmetic
+// IPV4 addresses use the low 32 bits.
+type uint128 struct {
+	hi uint64
+	lo uint64
+}
+
+// u128FromAddr get the integer value of an address
+func u128FromAddr(addr netip.Addr) uint128 {
+	if addr.Is4() {
+		b := addr.As4()
+		return uint128{lo: uint64(binary.BigEndian.Uint32(b[:]))}
+	}
+	b := addr.As16()
+
+	return uint128{hi: binary.BigEndian.Uint64(b[:8]), lo: binary.BigEndian.Uint64(b[8:])}
+}
+
+// addr get the address for the value in a family with bitLen bits
+func (u uint128) addr(bitLen int) netip.Addr {
+	if bitLen == 32 {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(u.lo))
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.hi)
+	binary.BigEndian.PutUint64(b[8:], u.lo)
+
+	return netip.AddrFrom16(b)
+}
+
+// hostMask get a value with the low n bits set
+func hostMask(n int) uint128 {
+	switch {
+	case n <= 0:
+		return uint128{}
+	case n < 64:
+		return uint128{lo: 1<<n - 1}
+	case n < 128:
+		return uint128{hi: 1<<(n-64) - 1, lo: ^uint64(0)}
+	}
+
+	return uint128{hi: ^uint64(0), lo: ^uint64(0)}
+}
+
+// add add two values, wrapping on overflow
+func (u uint128) add(v uint128) uint128 {
+	lo, carry := bits.Add64(u.lo, v.lo, 0)
+	hi, _ := bits.Add64(u.hi, v.hi, carry)
+
+	return uint128{hi: hi, lo: lo}
+}
+
+// lsh shift a value left by n bits, losing bits shifted past the top
+func (u uint128) lsh(n int) uint128 {
+	switch {
+	case n <= 0:
+		return u
+	case n < 64:
+		return uint128{hi: u.hi<<n | u.lo>>(64-n), lo: u.lo << n}
+	case n < 128:
+		return uint128{hi: u.lo << (n - 64)}
+	}
+
+	return uint128{}
+}
+
+// or get the bitwise or of two values
+func (u uint128) or(v uint128) uint128 {
+	return uint128{hi: u.hi | v.hi, lo: u.lo | v.lo}
+}
+
+// cmp compare two values, -1 if u is less, 0 if equal and 1 if greater
+func (u uint128) cmp(v uint128) int {
+	switch {
+	case u.hi < v.hi:
+		return -1
+	case u.hi > v.hi:
+		return 1
+	case u.lo < v.lo:
+		return -1
+	case u.lo > v.lo:
+		return 1
+	}
+
+	return 0
+}
+
+// big get the value as a big integer
+func (u uint128) big() *big.Int {
+	value := new(big.Int).SetUint64(u.hi)
+	value.Lsh(value, 64)
+
+	return value.Or(value, new(big.Int).SetUint64(u.lo))
+}
+
+// u128FromBig get the value of a big integer, false if it is negative or too large
+func u128FromBig(value *big.Int) (u uint128, ok bool) {
+	if value.Sign() < 0 || value.BitLen() > 128 {
+		return
+	}
+	u.lo = new(big.Int).And(value, new(big.Int).SetUint64(^uint64(0))).Uint64()
+	u.hi = new(big.Int).Rsh(value, 64).Uint64()
+
+	return u, true
+}
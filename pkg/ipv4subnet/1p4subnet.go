@@ -2,10 +2,8 @@ package ipv4subnet
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"math"
-	"os"
+	"slices"
 	"strings"
 
 	"net/netip"
@@ -33,6 +31,13 @@ func NewRange(first, last netip.Addr) Range {
 	return r
 }
 
+// NewRangeFromPrefix make a new range covering a prefix
+func NewRangeFromPrefix(prefix netip.Prefix) Range {
+	prefix = prefix.Masked()
+
+	return NewRange(prefix.Addr(), prefixLast(prefix))
+}
+
 // First get first address in range
 func (r *Range) First() netip.Addr {
 	return r.first
@@ -48,6 +53,46 @@ func (r *Range) String() string {
 	return fmt.Sprintf("%s-%s", r.first.String(), r.last.String())
 }
 
+// ParseRange parse a range in the form first-last
+func ParseRange(value string) (r Range, err error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		err = fmt.Errorf("%w: invalid range %q, expected first-last", ErrInvalidPrefix, value)
+		return
+	}
+	first, err := netip.ParseAddr(strings.TrimSpace(parts[0]))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidPrefix, err)
+		return
+	}
+	last, err := netip.ParseAddr(strings.TrimSpace(parts[1]))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidPrefix, err)
+		return
+	}
+	first, last = first.Unmap(), last.Unmap()
+	if first.BitLen() != last.BitLen() {
+		err = fmt.Errorf("%w: range %q mixes IPV4 and IPV6 addresses", ErrWrongFamily, value)
+		return
+	}
+	if last.Less(first) {
+		err = fmt.Errorf("%w: range %q has first address after last", ErrInvalidPrefix, value)
+		return
+	}
+	r = NewRange(first, last)
+
+	return
+}
+
+// Prefixes get the minimal list of prefixes exactly covering the range
+func (r *Range) Prefixes() (prefixes []netip.Prefix) {
+	if !r.first.IsValid() || r.first.BitLen() != r.last.BitLen() || r.last.Less(r.first) {
+		return
+	}
+
+	return rangePrefixes(r.first, r.last)
+}
+
 // Subnet an IP subnet
 type Subnet struct {
 	name          string
@@ -138,12 +183,7 @@ func NewFromAddrAndBits(addr string, bits int) (subnet *Subnet, err error) {
 
 // NewNamedFromPrefix new with name using incoming prefix
 func NewNamedFromPrefix(prefix string, name string) (subnet *Subnet, err error) {
-	p, err := netip.ParseAddr(prefix)
-	if err != nil {
-		return
-	}
-
-	subnet, err = newSubnet(p.String(), p.BitLen())
+	subnet, err = NewFromPrefix(prefix)
 	if err != nil {
 		return
 	}
@@ -156,6 +196,7 @@ func NewNamedFromPrefix(prefix string, name string) (subnet *Subnet, err error)
 func NewFromPrefix(prefix string) (subnet *Subnet, err error) {
 	p, err := netip.ParsePrefix(prefix)
 	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidPrefix, err)
 		return
 	}
 
@@ -169,30 +210,17 @@ func NewFromPrefix(prefix string) (subnet *Subnet, err error) {
 
 // newSubnet new subnet with prefix ip and network bits
 func newSubnet(addr string, bits int) (subnet *Subnet, err error) {
-	errMsg := "invalid prefix"
-
-	subnet = new(Subnet)
-	subnet.netMaskPrefix, err = netip.ParsePrefix(fmt.Sprintf("255.255.255.255/%d", bits))
-	if err != nil {
-		fmt.Println(err)
-	}
-	subnet.netMaskPrefix = subnet.netMaskPrefix.Masked()
-
-	var pfx netip.Prefix
-	pfx, err = netip.ParsePrefix(fmt.Sprintf("%s/%d", addr, bits))
+	pfx, err := netip.ParsePrefix(fmt.Sprintf("%s/%d", addr, bits))
 	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidPrefix, err)
 		return
 	}
-	pfx = pfx.Masked()
 
-	if !pfx.IsValid() {
-		return nil, errors.New(errMsg)
-	}
-
-	if pfx.Addr().Is6() {
-		return nil, errors.New("subnet too large for current implementation")
+	if !pfx.Addr().Is4() {
+		err = fmt.Errorf("%w: %s is not an IPV4 prefix", ErrWrongFamily, pfx)
+		return
 	}
-	subnet.prefix = pfx
+	subnet = subnetFromPrefix(pfx)
 
 	return
 }
@@ -247,29 +275,26 @@ func (s *Subnet) ClassHostBits() int {
 	return s.maxClassBits() - s.Prefix().Bits()
 }
 
-// TotalHosts total hosts in subnet
+// TotalHosts total hosts in all networks of the subnet's size in its class block
 func (s *Subnet) TotalHosts() int64 {
 	return s.Hosts() * s.Networks()
 }
 
-// Hosts bits remaining in mask block
+// Hosts number of addresses in the subnet
 func (s *Subnet) Hosts() int64 {
-	if s.Prefix().Bits()%8 == 0 {
-		// This may or may not be the proper solution
-		if s.Prefix().Bits() == 32 {
-			return 1
-		}
-		return int64(
-			(math.Exp2(float64(32) - float64(s.Prefix().Bits()))) / float64(s.Networks()),
-		)
-	}
-	return int64((math.Exp2(float64(32 - s.Prefix().Bits()))))
+	return int64(1) << (32 - s.Prefix().Bits())
 }
 
 // UsableHosts number of usable hosts
+// A /31 is a point to point link with both addresses usable (RFC 3021) and a
+// /32 is a host route with its single address usable. Other subnets lose the
+// network and broadcast addresses.
 func (s *Subnet) UsableHosts() int64 {
-	if s.Hosts() < 2 {
-		return 0
+	switch s.Prefix().Bits() {
+	case 31:
+		return 2
+	case 32:
+		return 1
 	}
 	return s.Hosts() - 2
 }
@@ -327,7 +352,7 @@ func (s *Subnet) First() (ip netip.Addr, err error) {
 
 // Last get last IP for subnet
 func (s *Subnet) Last() (ip netip.Addr) {
-	ip, err := ip4util.AddToAddr(s.prefix.Addr(), int32(s.Hosts()-1))
+	ip, err := ip4util.AddToAddr(s.prefix.Addr(), uint32(s.Hosts()-1))
 	if err != nil {
 		return netip.Addr{}
 	}
@@ -335,76 +360,64 @@ func (s *Subnet) Last() (ip netip.Addr) {
 	return
 }
 
-// NetworkAddr get last IP for subnet
+// NetworkAddr get network address for subnet, i.e. the first IP
 func (s *Subnet) NetworkAddr() (ip netip.Addr) {
-	return s.Last()
+	return s.prefix.Masked().Addr()
 }
 
-// Networks number of subnets
+// Networks number of subnets of the subnet's size in its class block
 func (s *Subnet) Networks() int64 {
 	bits := s.Prefix().Bits() - s.startClassBits()
 
-	return int64(math.Exp2(float64(bits)))
+	return int64(1) << bits
 }
 
 // UsableIPs get usable ips for subnet
 func (s *Subnet) UsableIPs() (ips []netip.Addr, err error) {
-	errMsg := "empty ip list for subnet"
-	ips, err = s.IPs()
-	if err != nil {
-		return
-	}
+	ips = slices.Collect(s.UsableIPsSeq())
 	if len(ips) == 0 {
-		err = errors.New(errMsg)
+		err = fmt.Errorf("%w: empty ip list for subnet %s", ErrInvalidPrefix, s.CIDR())
 		ips = []netip.Addr{}
 		return
 	}
-	ips = ips[1 : len(ips)-1]
 
 	return
 }
 
 // IPs get ips for subnet
 func (s *Subnet) IPs() (ips []netip.Addr, err error) {
-	errMsg := "empty ip list for subnet"
-	ip := s.Prefix().Addr()
-	ips = append(ips, ip)
-
-	for j := 0; j < int(s.TotalHosts()); j++ {
-		ip = ip.Next()
-		if (ip == netip.Addr{}) {
-			err = errors.New(errMsg)
-			ips = []netip.Addr{}
-			return
-		}
-		ips = append(ips, ip)
-	}
+	ips = slices.Collect(s.IPsSeq())
 
 	return
 }
 
 // UsableIPRange get range of IPs usable for hosts
+// A /31 has both addresses usable (RFC 3021) and a /32 its single address.
 func (s *Subnet) UsableIPRange() (r Range, err error) {
-	ip := s.Prefix().Addr()
-	startIP := ip
-	ip, err = ip4util.AddToAddr(ip, int32(s.TotalHosts()))
-	if err != nil {
+	first := s.Prefix().Addr()
+	last := s.Last()
+	if !last.IsValid() {
+		err = fmt.Errorf("%w: no last address for subnet %s", ErrAddressOverflow, s.CIDR())
+		return
+	}
+	if s.Prefix().Bits() >= 31 {
+		r = NewRange(first, last)
 		return
 	}
-	r = NewRange(startIP.Next(), ip.Prev())
+	r = NewRange(first.Next(), last.Prev())
 
 	return
 }
 
 // IPRange get subnet range
 func (s *Subnet) IPRange() (r Range, err error) {
-	ip := s.Prefix().Addr()
-	startIP := ip
-	ip, err = ip4util.AddToAddr(ip, int32(s.TotalHosts()))
-	if err != nil {
+	first := s.Prefix().Addr()
+	last := s.Last()
+	if !last.IsValid() {
+		err = fmt.Errorf("%w: no last address for subnet %s", ErrAddressOverflow, s.CIDR())
 		return
 	}
-	r = NewRange(startIP, ip)
+	r = NewRange(first, last)
 
 	return
 }
@@ -421,20 +434,11 @@ func (s *Subnet) Subnets() (subnets []*Subnet, err error) {
 
 // subnets split a subnet into smaller secondary subnets
 func (s *Subnet) subnets(secondarySubnet *Subnet) (subnets []*Subnet, err error) {
-	ranges, err := s.ipRanges(secondarySubnet)
+	seq, err := s.subnetsSeq(secondarySubnet)
 	if err != nil {
 		return
 	}
-
-	for _, r := range ranges {
-		prefix := fmt.Sprintf("%s/%d", r.First(), secondarySubnet.Prefix().Bits())
-		s, err = NewFromPrefix(prefix)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		subnets = append(subnets, s)
-	}
+	subnets = slices.Collect(seq)
 
 	return
 }
@@ -451,33 +455,19 @@ func (s *Subnet) IPRanges() (ranges []Range, err error) {
 
 // EffectiveNetworks number of networks
 func (s *Subnet) EffectiveNetworks(secondarySubnet *Subnet) int64 {
-	ratio := int64(math.Exp2(float64(secondarySubnet.Prefix().Bits() - s.Prefix().Bits())))
-
-	return s.Networks() * ratio
+	if secondarySubnet.Prefix().Bits() < s.Prefix().Bits() {
+		return 0
+	}
+	return s.Networks() << (secondarySubnet.Prefix().Bits() - s.Prefix().Bits())
 }
 
 // ipRanges get the ranges for a subnet splitting by secondary subnet (can be self)
 func (s *Subnet) ipRanges(secondarySubnet *Subnet) (ranges []Range, err error) {
-	// Can't subdivide to smaller prefixed subnet
-	if secondarySubnet.Prefix().Bits() < s.Prefix().Bits() {
-		err = fmt.Errorf("Subnet to split to has more bits %d than parent %d", s.Prefix().Bits(), secondarySubnet.Prefix().Bits())
+	seq, err := s.ipRangesSeq(secondarySubnet)
+	if err != nil {
 		return
 	}
-	ranges = []Range{}
-	ip := s.IP()
-	ipStart := ip
-
-	ratio := int(math.Exp2(float64(secondarySubnet.Prefix().Bits() - s.Prefix().Bits())))
-	for j := 0; j < int(s.Networks()); j++ {
-		for r := 0; r < ratio; r++ {
-			ip, err := ip4util.AddToAddr(ipStart, int32(secondarySubnet.Hosts()-1))
-			if err != nil {
-				//return
-			}
-			ranges = append(ranges, NewRange(ipStart, ip))
-			ipStart = ip.Next()
-		}
-	}
+	ranges = append([]Range{}, slices.Collect(seq)...)
 
 	return
 }
@@ -0,0 +1,15 @@
+package ipv4subnet
+
+import "github.com/imarsman/iptools/pkg/util"
+
+// Errors returned wrapped by this package, usable with errors.Is
+var (
+	// ErrInvalidPrefix a prefix or address could not be parsed or is out of range
+	ErrInvalidPrefix = util.ErrInvalidPrefix
+	// ErrSplitToFewerBits a subnet was split into subnets with fewer prefix bits
+	ErrSplitToFewerBits = util.ErrSplitToFewerBits
+	// ErrAddressOverflow a calculation went past the end of the address space
+	ErrAddressOverflow = util.ErrAddressOverflow
+	// ErrWrongFamily an IPV6 address was given where IPV4 was expected
+	ErrWrongFamily = util.ErrWrongFamily
+)
@@ -0,0 +1,144 @@
+package ipv4subnet
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// FreeRanges get the ranges in the subnet not covered by any allocated prefix
+// Allocations are clipped to the subnet so ones partly or wholly outside it are allowed.
+func (s *Subnet) FreeRanges(allocated []netip.Prefix) (free []Range, err error) {
+	return freeRanges(s.Prefix(), allocated)
+}
+
+// FreeSubnets get the space in the subnet not covered by any allocated prefix as maximal subnets
+func (s *Subnet) FreeSubnets(allocated []netip.Prefix) (free []*Subnet, err error) {
+	prefixes, err := FreePrefixes(s.Prefix(), allocated)
+	if err != nil {
+		return
+	}
+	for _, prefix := range prefixes {
+		free = append(free, subnetFromPrefix(prefix))
+	}
+
+	return
+}
+
+// FirstFree get the lowest free subnet with prefix bits in the subnet
+func (s *Subnet) FirstFree(allocated []netip.Prefix, bits int) (subnet *Subnet, err error) {
+	prefix, err := FindFreePrefix(s.Prefix(), allocated, bits, false)
+	if err != nil {
+		return
+	}
+	subnet = subnetFromPrefix(prefix)
+
+	return
+}
+
+// BestFitFree get a free subnet with prefix bits from the smallest free block it fits in
+// This leaves larger free blocks whole for later allocations. Ties go to the lowest block.
+func (s *Subnet) BestFitFree(allocated []netip.Prefix, bits int) (subnet *Subnet, err error) {
+	prefix, err := FindFreePrefix(s.Prefix(), allocated, bits, true)
+	if err != nil {
+		return
+	}
+	subnet = subnetFromPrefix(prefix)
+
+	return
+}
+
+// FreePrefixes get the space in an IPV4 or IPV6 parent not covered by any allocated prefix as maximal prefixes
+func FreePrefixes(parent netip.Prefix, allocated []netip.Prefix) (free []netip.Prefix, err error) {
+	ranges, err := freeRanges(parent, allocated)
+	if err != nil {
+		return
+	}
+	for _, r := range ranges {
+		free = append(free, r.Prefixes()...)
+	}
+
+	return
+}
+
+// FindFreePrefix get a free prefix with prefix bits in an IPV4 or IPV6 parent by first or best fit
+// Any aligned block in free space lies inside one of the maximal free prefixes, so
+// the block is always the start of one of them.
+func FindFreePrefix(parent netip.Prefix, allocated []netip.Prefix, bits int, bestFit bool) (prefix netip.Prefix, err error) {
+	parent = parent.Masked()
+	if bits > parent.Addr().BitLen() {
+		err = fmt.Errorf("%w: /%d is longer than %d bits", ErrInvalidPrefix, bits, parent.Addr().BitLen())
+		return
+	}
+	if bits < parent.Bits() {
+		err = fmt.Errorf("%w: /%d from %s", ErrSplitToFewerBits, bits, parent)
+		return
+	}
+	free, err := FreePrefixes(parent, allocated)
+	if err != nil {
+		return
+	}
+
+	var block netip.Prefix
+	for _, f := range free {
+		if f.Bits() > bits {
+			continue
+		}
+		if !block.IsValid() || bestFit && f.Bits() > block.Bits() {
+			block = f
+		}
+		if !bestFit {
+			break
+		}
+	}
+	if !block.IsValid() {
+		err = fmt.Errorf("no free /%d in %s", bits, parent)
+		return
+	}
+	prefix = netip.PrefixFrom(block.Addr(), bits)
+
+	return
+}
+
+// freeRanges get the ranges in parent not covered by any allocated prefix
+func freeRanges(parent netip.Prefix, allocated []netip.Prefix) (free []Range, err error) {
+	parent = parent.Masked()
+	first, last := parent.Addr(), prefixLast(parent)
+
+	used := []Range{}
+	for _, prefix := range allocated {
+		if first.Is4() {
+			prefix = util.UnmapPrefix(prefix)
+		}
+		if prefix.Addr().BitLen() != first.BitLen() {
+			err = fmt.Errorf("%w: allocation %s is not in the same family as %s", ErrWrongFamily, prefix, parent)
+			return
+		}
+		if !prefix.Overlaps(parent) {
+			continue
+		}
+		r := NewRangeFromPrefix(prefix)
+		if r.First().Less(first) {
+			r = NewRange(first, r.Last())
+		}
+		if last.Less(r.Last()) {
+			r = NewRange(r.First(), last)
+		}
+		used = append(used, r)
+	}
+
+	next := first
+	for _, r := range MergeRanges(used) {
+		if next.Less(r.First()) {
+			free = append(free, NewRange(next, r.First().Prev()))
+		}
+		if r.Last() == last {
+			return
+		}
+		next = r.Last().Next()
+	}
+	free = append(free, NewRange(next, last))
+
+	return
+}
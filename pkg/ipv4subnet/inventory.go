@@ -0,0 +1,191 @@
+package ipv4subnet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"path/filepath"
+	"strings"
+
+	"github.com/imarsman/iptools/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// TextFormat one prefix per line optionally followed by a name
+	TextFormat = "text"
+	// CSVFormat comma separated prefix and name columns
+	CSVFormat = "csv"
+	// YAMLFormat a list of prefixes or of maps with cidr and name keys (JSON also works)
+	YAMLFormat = "yaml"
+)
+
+// InventoryFormat get the inventory format for a file path by extension
+func InventoryFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSVFormat
+	case ".yaml", ".yml", ".json":
+		return YAMLFormat
+	default:
+		return TextFormat
+	}
+}
+
+// ParseNamedPrefix parse a prefix or a single address as a full length prefix
+func ParseNamedPrefix(value, name string) (namedPrefix NamedPrefix, err error) {
+	value = strings.TrimSpace(value)
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		var addr netip.Addr
+		addr, err = netip.ParseAddr(value)
+		if err != nil {
+			err = fmt.Errorf("%w: %q", ErrInvalidPrefix, value)
+			return
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	namedPrefix = NamedPrefix{Name: strings.TrimSpace(name), Prefix: util.UnmapPrefix(prefix)}
+
+	return
+}
+
+// ReadInventory read a list of optionally named prefixes in text, CSV or YAML format
+func ReadInventory(reader io.Reader, format string) (prefixes []NamedPrefix, err error) {
+	switch format {
+	case TextFormat, "":
+		return readTextInventory(reader)
+	case CSVFormat:
+		return readCSVInventory(reader)
+	case YAMLFormat:
+		return readYAMLInventory(reader)
+	default:
+		err = fmt.Errorf("unknown inventory format %s", format)
+		return
+	}
+}
+
+// readTextInventory read lines with a prefix followed by an optional name
+// Anything after # is a comment.
+func readTextInventory(reader io.Reader) (prefixes []NamedPrefix, err error) {
+	scanner := bufio.NewScanner(reader)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		var prefix NamedPrefix
+		prefix, err = ParseNamedPrefix(fields[0], strings.Join(fields[1:], " "))
+		if err != nil {
+			err = fmt.Errorf("line %d: %w", line, err)
+			return
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	err = scanner.Err()
+
+	return
+}
+
+// readCSVInventory read rows with prefix and name columns
+// A header row naming cidr or prefix and name columns is used if present,
+// otherwise the first column is the prefix and the second the name.
+func readCSVInventory(reader io.Reader) (prefixes []NamedPrefix, err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.Comment = '#'
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return
+	}
+
+	prefixColumn, nameColumn := 0, 1
+	for i, record := range records {
+		if i == 0 {
+			header := false
+			for j, cell := range record {
+				switch strings.ToLower(strings.TrimSpace(cell)) {
+				case "cidr", "prefix", "subnet":
+					prefixColumn, header = j, true
+				case "name":
+					nameColumn, header = j, true
+				}
+			}
+			if header {
+				continue
+			}
+		}
+		if len(record) <= prefixColumn || strings.TrimSpace(record[prefixColumn]) == "" {
+			continue
+		}
+		name := ""
+		if nameColumn < len(record) {
+			name = record[nameColumn]
+		}
+		var prefix NamedPrefix
+		prefix, err = ParseNamedPrefix(record[prefixColumn], name)
+		if err != nil {
+			err = fmt.Errorf("row %d: %w", i+1, err)
+			return
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return
+}
+
+// inventoryEntry a YAML inventory entry that is either a prefix or a map
+type inventoryEntry struct {
+	CIDR   string `yaml:"cidr"`
+	Prefix string `yaml:"prefix"`
+	Name   string `yaml:"name"`
+}
+
+// UnmarshalYAML allow an entry to be a plain prefix string
+func (e *inventoryEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		e.CIDR = value
+		return nil
+	}
+	type plain inventoryEntry
+
+	return unmarshal((*plain)(e))
+}
+
+// readYAMLInventory read a list of prefixes or maps with cidr and name keys
+func readYAMLInventory(reader io.Reader) (prefixes []NamedPrefix, err error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return
+	}
+	entries := []inventoryEntry{}
+	err = yaml.Unmarshal(bytes, &entries)
+	if err != nil {
+		return
+	}
+	for i, entry := range entries {
+		value := entry.CIDR
+		if value == "" {
+			value = entry.Prefix
+		}
+		var prefix NamedPrefix
+		prefix, err = ParseNamedPrefix(value, entry.Name)
+		if err != nil {
+			err = fmt.Errorf("entry %d: %w", i+1, err)
+			return
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return
+}
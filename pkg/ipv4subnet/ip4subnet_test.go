@@ -1,12 +1,15 @@
 package ipv4subnet
 
 import (
+	"errors"
 	"fmt"
 	"net/netip"
+	"strings"
 	"testing"
 	"time"
 
 	ip4util "github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/util"
 	"github.com/matryer/is"
 )
 
@@ -33,6 +36,30 @@ func TestNewSubnet(t *testing.T) {
 	}
 }
 
+func TestNetworkAddr(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		prefix    string
+		network   string
+		broadcast string
+	}{
+		{"10.32.0.64/26", "10.32.0.64", "10.32.0.127"},
+		{"192.168.1.77/24", "192.168.1.0", "192.168.1.255"},
+		{"172.16.0.0/12", "172.16.0.0", "172.31.255.255"},
+		{"10.32.0.0/31", "10.32.0.0", "10.32.0.1"},
+	}
+	for _, test := range tests {
+		s, err := NewFromPrefix(test.prefix)
+		is.NoErr(err)
+		t.Log(s, s.NetworkAddr(), s.BroadcastAddr())
+		// the network address is the first address, not the broadcast address
+		is.Equal(s.NetworkAddr().String(), test.network)
+		is.Equal(s.BroadcastAddr().String(), test.broadcast)
+		is.True(s.NetworkAddr() != s.BroadcastAddr())
+	}
+}
+
 func TestNetworks(t *testing.T) {
 	is := is.New(t)
 	p, err := netip.ParsePrefix("192.24.12.0/18")
@@ -163,6 +190,63 @@ func TestIPStringSplit(t *testing.T) {
 	}
 }
 
+// TestParsePrefix test parsing of prefixes written as in router configs and tickets
+func TestParsePrefix(t *testing.T) {
+	is := is.New(t)
+
+	for _, value := range []string{
+		"10.0.0.0/24",
+		"10.0.0.0 255.255.255.0",
+		"10.0.0.0  0.0.0.255",
+		"10.0.0.0/255.255.255.0",
+		"0x0a000000/0xffffff00",
+		"0x0A000000/24",
+		"167772160/24",
+		"00001010.00000000.00000000.00000000/24",
+		"00001010000000000000000000000000/11111111111111111111111100000000",
+	} {
+		prefix, err := ip4util.ParsePrefix(value)
+		t.Log(value, prefix, err)
+		is.NoErr(err)
+		is.Equal(prefix.String(), "10.0.0.0/24")
+	}
+
+	prefix, err := ip4util.ParsePrefix("0x0a000001")
+	is.NoErr(err)
+	is.Equal(prefix.String(), "10.0.0.1/32")
+	prefix, err = ip4util.ParsePrefix("2001:db8::/32")
+	is.NoErr(err)
+	is.Equal(prefix.String(), "2001:db8::/32")
+	// a valid netmask is never taken as a wildcard mask
+	prefix, err = ip4util.ParsePrefix("10.0.0.0 0.0.0.0")
+	is.NoErr(err)
+	is.Equal(prefix.Bits(), 0)
+
+	for _, value := range []string{
+		"10.0.0.0 255.0.255.0",
+		"10.0.0.0/33",
+		"10.0.0.0/63",
+		"10.0.0.0/255",
+		"167772160 4294967040",
+		"10.0.0.0 0.255.0.255",
+		"0x1a000000a/24",
+		"0000101000000000000000000000000/24",
+		"00001010.00000000.00000000.0000000/24",
+		"10.0.0.0 255.255.255.0 extra",
+		"2001:db8::/ffff::",
+	} {
+		_, err := ip4util.ParsePrefix(value)
+		t.Log(err)
+		is.True(errors.Is(err, util.ErrInvalidPrefix))
+	}
+
+	bytes, err := ip4util.BinaryIP4StrToBytes("01100011111011000010000000000001")
+	is.NoErr(err)
+	is.Equal(bytes, []byte{99, 236, 32, 1})
+	_, err = ip4util.BinaryIP4StrToBytes("0110001111101100001000000000000x")
+	is.True(err != nil)
+}
+
 // go test -bench=. -benchmem
 func BenchmarkNewSubnet(b *testing.B) {
 	is := is.New(b)
@@ -180,3 +264,544 @@ func BenchmarkSubnetSplit(b *testing.B) {
 	is.True(len(subnets) == 16)
 	s.IPRanges()
 }
+
+func TestVLSM(t *testing.T) {
+	is := is.New(t)
+	s, err := NewFromPrefix("10.0.0.0/22")
+	is.NoErr(err)
+
+	requirements := []Requirement{
+		{Name: "link1", Hosts: 2},
+		{Name: "sales", Hosts: 500},
+		{Name: "eng", Hosts: 60},
+		{Name: "link2", Hosts: 2},
+	}
+	allocated, free, err := s.VLSM(requirements)
+	is.NoErr(err)
+	is.Equal(len(allocated), 4)
+	is.Equal(allocated[0].Name(), "sales")
+	is.Equal(allocated[0].String(), "10.0.0.0/23")
+	is.Equal(allocated[1].String(), "10.0.2.0/26")
+	is.Equal(allocated[2].String(), "10.0.2.64/30")
+	is.Equal(allocated[3].String(), "10.0.2.68/30")
+	for _, s := range allocated {
+		t.Log(s.Name(), s.String(), s.UsableHosts())
+	}
+	freeList := []string{}
+	for _, s := range free {
+		freeList = append(freeList, s.String())
+	}
+	t.Log("free", freeList)
+	is.Equal(freeList, []string{"10.0.2.72/29", "10.0.2.80/28", "10.0.2.96/27", "10.0.2.128/25", "10.0.3.0/24"})
+
+	_, _, err = s.VLSM([]Requirement{{Name: "big", Hosts: 1000}, {Name: "more", Hosts: 60}})
+	is.True(errors.Is(err, ErrInvalidPrefix))
+	t.Log(err)
+	_, _, err = s.VLSM([]Requirement{{Name: "a", Hosts: 2}, {Name: "a", Hosts: 2}})
+	is.True(errors.Is(err, ErrInvalidPrefix))
+
+	requirement, err := ParseRequirement("sales:500")
+	is.NoErr(err)
+	is.Equal(requirement, Requirement{Name: "sales", Hosts: 500})
+	_, err = ParseRequirement("sales")
+	is.True(errors.Is(err, ErrInvalidPrefix))
+	_, err = ParseRequirement("sales:none")
+	is.True(errors.Is(err, ErrInvalidPrefix))
+}
+
+func TestSummarize(t *testing.T) {
+	is := is.New(t)
+
+	parse := func(list ...string) (prefixes []netip.Prefix) {
+		for _, item := range list {
+			p, err := netip.ParsePrefix(item)
+			is.NoErr(err)
+			prefixes = append(prefixes, p)
+		}
+		return
+	}
+	toStrings := func(prefixes []netip.Prefix) (list []string) {
+		for _, p := range prefixes {
+			list = append(list, p.String())
+		}
+		return
+	}
+
+	summary := Summarize(parse("10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24", "10.0.1.32/27"))
+	is.Equal(toStrings(summary), []string{"10.0.0.0/22"})
+
+	summary = Summarize(parse("10.0.1.0/24", "10.0.2.0/24", "192.168.0.0/27", "192.168.0.32/27", "192.168.0.96/27"))
+	is.Equal(toStrings(summary), []string{"10.0.1.0/24", "10.0.2.0/24", "192.168.0.0/26", "192.168.0.96/27"})
+
+	summary = Summarize(parse("2001:db8::/49", "2001:db8:0:8000::/49", "10.0.0.0/8", "2001:db8:1::/48"))
+	is.Equal(toStrings(summary), []string{"10.0.0.0/8", "2001:db8::/47"})
+
+	summary = Summarize(parse("0.0.0.0/1", "128.0.0.0/1", "255.255.255.255/32"))
+	is.Equal(toStrings(summary), []string{"0.0.0.0/0"})
+}
+
+func TestRangePrefixes(t *testing.T) {
+	is := is.New(t)
+
+	r, err := ParseRange("10.1.3.17-10.1.9.200")
+	is.NoErr(err)
+	list := []string{}
+	for _, p := range r.Prefixes() {
+		list = append(list, p.String())
+	}
+	t.Log(list)
+	is.Equal(list, []string{
+		"10.1.3.17/32", "10.1.3.18/31", "10.1.3.20/30", "10.1.3.24/29", "10.1.3.32/27", "10.1.3.64/26",
+		"10.1.3.128/25", "10.1.4.0/22", "10.1.8.0/24", "10.1.9.0/25", "10.1.9.128/26", "10.1.9.192/29",
+		"10.1.9.200/32",
+	})
+
+	r, err = ParseRange("0.0.0.0-255.255.255.255")
+	is.NoErr(err)
+	is.Equal(len(r.Prefixes()), 1)
+	is.Equal(r.Prefixes()[0].String(), "0.0.0.0/0")
+
+	r, err = ParseRange("2001:db8::-2001:db8::1:ffff")
+	is.NoErr(err)
+	is.Equal(len(r.Prefixes()), 1)
+	is.Equal(r.Prefixes()[0].String(), "2001:db8::/111")
+
+	_, err = ParseRange("10.0.0.9-10.0.0.1")
+	is.True(errors.Is(err, ErrInvalidPrefix))
+	_, err = ParseRange("10.0.0.1-2001:db8::")
+	is.True(errors.Is(err, ErrWrongFamily))
+	_, err = ParseRange("10.0.0.1")
+	is.True(errors.Is(err, ErrInvalidPrefix))
+}
+
+func TestOverlaps(t *testing.T) {
+	is := is.New(t)
+
+	text := `
+10.0.0.0/8 corp a # acquired
+10.1.0.0/16 corp a branch
+192.168.1.0/24 lab
+10.1.0.0/16 corp b
+2001:db8::/32 v6
+2001:db8:1::/48
+`
+	prefixes, err := ReadInventory(strings.NewReader(text), TextFormat)
+	is.NoErr(err)
+	is.Equal(len(prefixes), 6)
+	is.Equal(prefixes[0].Name, "corp a")
+
+	overlaps := Overlaps(prefixes)
+	for _, o := range overlaps {
+		t.Log(o.First.Prefix, o.First.Name, o.Relation, o.Second.Prefix, o.Second.Name, o.Shared.String())
+	}
+	is.Equal(len(overlaps), 4)
+	is.Equal(overlaps[0].Relation, NestedRelation)
+	is.Equal(overlaps[2].Relation, DuplicateRelation)
+	is.Equal(overlaps[2].Shared.String(), "10.1.0.0-10.1.255.255")
+	is.Equal(overlaps[3].Second.Prefix.String(), "2001:db8:1::/48")
+
+	csvText := "name,cidr\nlab,192.168.1.0/24\nhost,192.168.1.10\n"
+	prefixes, err = ReadInventory(strings.NewReader(csvText), CSVFormat)
+	is.NoErr(err)
+	is.Equal(len(prefixes), 2)
+	is.Equal(prefixes[1].Prefix.String(), "192.168.1.10/32")
+	is.Equal(len(Overlaps(prefixes)), 1)
+
+	yamlText := "- 10.0.0.0/24\n- cidr: 10.0.0.128/25\n  name: half\n"
+	prefixes, err = ReadInventory(strings.NewReader(yamlText), YAMLFormat)
+	is.NoErr(err)
+	is.Equal(prefixes[1].Name, "half")
+	is.Equal(len(Overlaps(prefixes)), 1)
+
+	// IPV4 mapped IPV6 entries are read as IPV4 so they overlap IPV4 entries
+	prefixes, err = ReadInventory(strings.NewReader("::ffff:10.0.0.0/120 mapped\n::ffff:10.0.0.5 host\n10.0.0.0/25\n"), TextFormat)
+	is.NoErr(err)
+	is.Equal(prefixes[0].Prefix, netip.MustParsePrefix("10.0.0.0/24"))
+	is.Equal(prefixes[1].Prefix, netip.MustParsePrefix("10.0.0.5/32"))
+	is.Equal(len(Overlaps(prefixes)), 3)
+
+	_, err = ReadInventory(strings.NewReader("10.0.0.0/33\n"), TextFormat)
+	is.True(err != nil)
+	is.Equal(InventoryFormat("plan.yml"), YAMLFormat)
+}
+
+func TestSeq(t *testing.T) {
+	is := is.New(t)
+
+	s, err := NewFromPrefix("10.0.0.0/8")
+	is.NoErr(err)
+	secondary, err := NewFromPrefix("10.0.0.0/30")
+	is.NoErr(err)
+
+	subnets, err := s.SecondarySubnetsSeq(secondary)
+	is.NoErr(err)
+	count := 0
+	for subnet := range subnets {
+		if count == 3 {
+			is.Equal(subnet.String(), "10.0.0.12/30")
+			is.Equal(subnet.SubnetMask().String(), "255.255.255.252")
+			break
+		}
+		count++
+	}
+
+	ranges, err := s.SecondaryIPRangesSeq(secondary)
+	is.NoErr(err)
+	for r := range ranges {
+		is.Equal(r.String(), "10.0.0.0-10.0.0.3")
+		break
+	}
+
+	_, err = secondary.SecondaryIPRangesSeq(s)
+	is.True(err != nil)
+
+	s, err = NewFromPrefix("192.168.1.0/29")
+	is.NoErr(err)
+	ips := []string{}
+	for ip := range s.UsableIPsSeq() {
+		ips = append(ips, ip.String())
+	}
+	is.Equal(len(ips), 6)
+	is.Equal(ips[0], "192.168.1.1")
+	is.Equal(ips[5], "192.168.1.6")
+
+	all, err := s.IPs()
+	is.NoErr(err)
+	is.Equal(len(all), 8)
+}
+
+// TestEveryPrefixLength check subnet math for every IPV4 prefix length
+func TestEveryPrefixLength(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		prefix      string
+		mask        string
+		hosts       int64
+		usableHosts int64
+		networks    int64
+		broadcast   string
+		usableRange string
+	}{
+		{"0.0.0.0/0", "0.0.0.0", 4294967296, 4294967294, 1, "255.255.255.255", "0.0.0.1-255.255.255.254"},
+		{"128.0.0.0/1", "128.0.0.0", 2147483648, 2147483646, 2, "255.255.255.255", "128.0.0.1-255.255.255.254"},
+		{"128.0.0.0/2", "192.0.0.0", 1073741824, 1073741822, 4, "191.255.255.255", "128.0.0.1-191.255.255.254"},
+		{"128.0.0.0/3", "224.0.0.0", 536870912, 536870910, 8, "159.255.255.255", "128.0.0.1-159.255.255.254"},
+		{"128.0.0.0/4", "240.0.0.0", 268435456, 268435454, 16, "143.255.255.255", "128.0.0.1-143.255.255.254"},
+		{"128.0.0.0/5", "248.0.0.0", 134217728, 134217726, 32, "135.255.255.255", "128.0.0.1-135.255.255.254"},
+		{"128.0.0.0/6", "252.0.0.0", 67108864, 67108862, 64, "131.255.255.255", "128.0.0.1-131.255.255.254"},
+		{"128.0.0.0/7", "254.0.0.0", 33554432, 33554430, 128, "129.255.255.255", "128.0.0.1-129.255.255.254"},
+		{"10.0.0.0/8", "255.0.0.0", 16777216, 16777214, 1, "10.255.255.255", "10.0.0.1-10.255.255.254"},
+		{"10.0.0.0/9", "255.128.0.0", 8388608, 8388606, 2, "10.127.255.255", "10.0.0.1-10.127.255.254"},
+		{"10.0.0.0/10", "255.192.0.0", 4194304, 4194302, 4, "10.63.255.255", "10.0.0.1-10.63.255.254"},
+		{"10.32.0.0/11", "255.224.0.0", 2097152, 2097150, 8, "10.63.255.255", "10.32.0.1-10.63.255.254"},
+		{"10.32.0.0/12", "255.240.0.0", 1048576, 1048574, 16, "10.47.255.255", "10.32.0.1-10.47.255.254"},
+		{"10.32.0.0/13", "255.248.0.0", 524288, 524286, 32, "10.39.255.255", "10.32.0.1-10.39.255.254"},
+		{"10.32.0.0/14", "255.252.0.0", 262144, 262142, 64, "10.35.255.255", "10.32.0.1-10.35.255.254"},
+		{"10.32.0.0/15", "255.254.0.0", 131072, 131070, 128, "10.33.255.255", "10.32.0.1-10.33.255.254"},
+		{"10.32.0.0/16", "255.255.0.0", 65536, 65534, 1, "10.32.255.255", "10.32.0.1-10.32.255.254"},
+		{"10.32.0.0/17", "255.255.128.0", 32768, 32766, 2, "10.32.127.255", "10.32.0.1-10.32.127.254"},
+		{"10.32.0.0/18", "255.255.192.0", 16384, 16382, 4, "10.32.63.255", "10.32.0.1-10.32.63.254"},
+		{"10.32.0.0/19", "255.255.224.0", 8192, 8190, 8, "10.32.31.255", "10.32.0.1-10.32.31.254"},
+		{"10.32.0.0/20", "255.255.240.0", 4096, 4094, 16, "10.32.15.255", "10.32.0.1-10.32.15.254"},
+		{"10.32.0.0/21", "255.255.248.0", 2048, 2046, 32, "10.32.7.255", "10.32.0.1-10.32.7.254"},
+		{"10.32.0.0/22", "255.255.252.0", 1024, 1022, 64, "10.32.3.255", "10.32.0.1-10.32.3.254"},
+		{"10.32.0.0/23", "255.255.254.0", 512, 510, 128, "10.32.1.255", "10.32.0.1-10.32.1.254"},
+		{"10.32.0.0/24", "255.255.255.0", 256, 254, 1, "10.32.0.255", "10.32.0.1-10.32.0.254"},
+		{"10.32.0.0/25", "255.255.255.128", 128, 126, 2, "10.32.0.127", "10.32.0.1-10.32.0.126"},
+		{"10.32.0.0/26", "255.255.255.192", 64, 62, 4, "10.32.0.63", "10.32.0.1-10.32.0.62"},
+		{"10.32.0.0/27", "255.255.255.224", 32, 30, 8, "10.32.0.31", "10.32.0.1-10.32.0.30"},
+		{"10.32.0.0/28", "255.255.255.240", 16, 14, 16, "10.32.0.15", "10.32.0.1-10.32.0.14"},
+		{"10.32.0.0/29", "255.255.255.248", 8, 6, 32, "10.32.0.7", "10.32.0.1-10.32.0.6"},
+		{"10.32.0.0/30", "255.255.255.252", 4, 2, 64, "10.32.0.3", "10.32.0.1-10.32.0.2"},
+		{"10.32.0.0/31", "255.255.255.254", 2, 2, 128, "10.32.0.1", "10.32.0.0-10.32.0.1"},
+		{"10.32.0.0/32", "255.255.255.255", 1, 1, 256, "10.32.0.0", "10.32.0.0-10.32.0.0"},
+	}
+
+	for _, test := range tests {
+		s, err := NewFromPrefix(test.prefix)
+		is.NoErr(err)
+		is.Equal(s.String(), test.prefix)
+		is.Equal(s.SubnetMask().String(), test.mask)
+		is.Equal(s.Hosts(), test.hosts)
+		is.Equal(s.UsableHosts(), test.usableHosts)
+		is.Equal(s.Networks(), test.networks)
+		is.Equal(s.BroadcastAddr().String(), test.broadcast)
+
+		usable, err := s.UsableIPRange()
+		is.NoErr(err)
+		is.Equal(usable.String(), test.usableRange)
+
+		r, err := s.IPRange()
+		is.NoErr(err)
+		is.Equal(r.First(), s.IP())
+		is.Equal(r.Last().String(), test.broadcast)
+
+		// every network of the subnet's size in its class block
+		ranges, err := s.IPRanges()
+		is.NoErr(err)
+		is.Equal(int64(len(ranges)), test.networks)
+		is.Equal(s.TotalHosts(), test.hosts*test.networks)
+		for i := 1; i < len(ranges); i++ {
+			is.Equal(ranges[i].First(), ranges[i-1].Last().Next())
+		}
+	}
+
+	// the last possible host route
+	s, err := NewFromPrefix("255.255.255.255/32")
+	is.NoErr(err)
+	is.Equal(s.BroadcastAddr().String(), "255.255.255.255")
+	is.Equal(s.UsableHosts(), int64(1))
+
+	// splitting the whole address space
+	s, err = NewFromPrefix("0.0.0.0/0")
+	is.NoErr(err)
+	secondary, err := NewFromPrefix("0.0.0.0/32")
+	is.NoErr(err)
+	is.Equal(s.EffectiveNetworks(secondary), int64(1)<<32)
+	is.Equal(secondary.EffectiveNetworks(s), int64(0))
+
+	_, err = ip4util.AddToAddr(netip.MustParseAddr("255.255.255.0"), 256)
+	is.True(err != nil)
+	addr, err := ip4util.AddToAddr(netip.MustParseAddr("0.0.0.0"), 4294967295)
+	is.NoErr(err)
+	is.Equal(addr.String(), "255.255.255.255")
+}
+
+func TestErrors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewFromPrefix("10.0.0.0/33")
+	t.Log(err)
+	is.True(errors.Is(err, ErrInvalidPrefix))
+
+	_, err = NewFromPrefix("2001:db8::/64")
+	t.Log(err)
+	is.True(errors.Is(err, ErrWrongFamily))
+
+	_, err = ParseRange("10.0.0.1-2001:db8::1")
+	t.Log(err)
+	is.True(errors.Is(err, ErrWrongFamily))
+
+	s, err := NewFromPrefix("10.0.0.0/24")
+	is.NoErr(err)
+	s2, err := NewFromPrefix("10.0.0.0/16")
+	is.NoErr(err)
+	_, err = s.SecondarySubnetsSeq(s2)
+	t.Log(err)
+	is.True(errors.Is(err, ErrSplitToFewerBits))
+
+	_, err = ip4util.AddToAddr(netip.MustParseAddr("255.255.255.255"), 1)
+	t.Log(err)
+	is.True(errors.Is(err, ErrAddressOverflow))
+	_, err = ip4util.AddToAddr(netip.MustParseAddr("2001:db8::1"), 1)
+	is.True(errors.Is(err, ErrWrongFamily))
+}
+
+func TestRelate(t *testing.T) {
+	is := is.New(t)
+
+	subnet := func(prefix string) *Subnet {
+		s, err := NewFromPrefix(prefix)
+		is.NoErr(err)
+		return s
+	}
+
+	s := subnet("10.0.1.0/24")
+	is.True(s.Contains(netip.MustParseAddr("10.0.1.200")))
+	is.True(!s.Contains(netip.MustParseAddr("10.0.2.0")))
+	is.True(s.ContainsSubnet(subnet("10.0.1.128/25")))
+	is.True(!s.ContainsSubnet(subnet("10.0.0.0/23")))
+	is.True(s.Overlaps(subnet("10.0.0.0/23")))
+	is.True(!s.Overlaps(subnet("10.0.2.0/24")))
+
+	supernet, err := s.Supernet(16)
+	is.NoErr(err)
+	is.Equal(supernet.CIDR(), "10.0.0.0/16")
+	_, err = s.Supernet(25)
+	is.True(errors.Is(err, ErrInvalidPrefix))
+
+	sibling, err := s.Sibling()
+	is.NoErr(err)
+	is.Equal(sibling.CIDR(), "10.0.0.0/24")
+	_, err = subnet("0.0.0.0/0").Sibling()
+	is.True(err != nil)
+
+	next, err := s.Next()
+	is.NoErr(err)
+	is.Equal(next.CIDR(), "10.0.2.0/24")
+	previous, err := s.Previous()
+	is.NoErr(err)
+	is.Equal(previous.CIDR(), "10.0.0.0/24")
+	_, err = subnet("255.255.255.0/24").Next()
+	is.True(errors.Is(err, ErrAddressOverflow))
+	_, err = subnet("0.0.0.0/24").Previous()
+	is.True(errors.Is(err, ErrAddressOverflow))
+
+	is.Equal(s.CommonPrefixBits(subnet("10.0.3.0/24")), 22)
+	is.Equal(s.CommonSupernet(subnet("10.0.3.0/24")).CIDR(), "10.0.0.0/22")
+	is.Equal(s.CommonPrefixBits(subnet("10.0.1.0/26")), 24)
+
+	is.Equal(s.Relate(subnet("10.0.1.0/24")), EqualRelation)
+	is.Equal(s.Relate(subnet("10.0.1.64/26")), ContainsRelation)
+	is.Equal(s.Relate(subnet("10.0.0.0/16")), InsideRelation)
+	is.Equal(s.Relate(subnet("10.0.0.0/24")), SiblingRelation)
+	is.Equal(s.Relate(subnet("10.0.2.0/24")), AdjacentRelation)
+	is.Equal(s.Relate(subnet("10.0.3.0/24")), DisjointRelation)
+	is.Equal(subnet("255.255.255.0/24").Relate(subnet("0.0.0.0/24")), DisjointRelation)
+}
+
+func TestFree(t *testing.T) {
+	is := is.New(t)
+
+	s, err := NewFromPrefix("10.20.0.0/16")
+	is.NoErr(err)
+	allocated := []netip.Prefix{
+		netip.MustParsePrefix("10.20.0.0/24"),
+		netip.MustParsePrefix("10.20.1.0/26"),
+		netip.MustParsePrefix("10.20.4.0/22"),
+		netip.MustParsePrefix("10.20.128.0/17"),
+		netip.MustParsePrefix("10.21.0.0/16"), // outside the parent
+	}
+
+	free, err := s.FreeSubnets(allocated)
+	is.NoErr(err)
+	list := []string{}
+	for _, f := range free {
+		list = append(list, f.CIDR())
+	}
+	t.Log(list)
+	is.Equal(list, []string{
+		"10.20.1.64/26", "10.20.1.128/25", "10.20.2.0/23",
+		"10.20.8.0/21", "10.20.16.0/20", "10.20.32.0/19", "10.20.64.0/18",
+	})
+
+	first, err := s.FirstFree(allocated, 26)
+	is.NoErr(err)
+	is.Equal(first.CIDR(), "10.20.1.64/26")
+	first, err = s.FirstFree(allocated, 24)
+	is.NoErr(err)
+	is.Equal(first.CIDR(), "10.20.2.0/24")
+	best, err := s.BestFitFree(allocated, 22)
+	is.NoErr(err)
+	is.Equal(best.CIDR(), "10.20.8.0/22")
+
+	_, err = s.FirstFree(allocated, 17)
+	is.True(err != nil)
+	_, err = s.FirstFree(allocated, 15)
+	is.True(errors.Is(err, ErrSplitToFewerBits))
+	_, err = s.FreeSubnets([]netip.Prefix{netip.MustParsePrefix("2001:db8::/32")})
+	is.True(errors.Is(err, ErrWrongFamily))
+
+	// the whole parent allocated
+	free, err = s.FreeSubnets([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	is.NoErr(err)
+	is.Equal(len(free), 0)
+	// nothing allocated
+	free, err = s.FreeSubnets(nil)
+	is.NoErr(err)
+	is.Equal(free[0].CIDR(), "10.20.0.0/16")
+}
+
+func TestTree(t *testing.T) {
+	is := is.New(t)
+
+	s, err := NewFromPrefix("10.0.0.0/22")
+	is.NoErr(err)
+	allocated := []NamedPrefix{
+		{Name: "web", Prefix: netip.MustParsePrefix("10.0.0.0/24")},
+		{Name: "db", Prefix: netip.MustParsePrefix("10.0.1.0/26")},
+	}
+
+	root, err := s.Tree(24, allocated, false)
+	is.NoErr(err)
+	lines := []string{}
+	root.Walk(func(node *TreeNode, depth int) {
+		lines = append(lines, fmt.Sprintf("%d %s %s %s", depth, node.Subnet.CIDR(), node.State, node.Name))
+	})
+	t.Log(strings.Join(lines, "\n"))
+	is.Equal(lines, []string{
+		"0 10.0.0.0/22 partial ",
+		"1 10.0.0.0/23 partial ",
+		"2 10.0.0.0/24 allocated web",
+		"2 10.0.1.0/24 partial ",
+		"1 10.0.2.0/23 free ",
+		"2 10.0.2.0/24 free ",
+		"2 10.0.3.0/24 free ",
+	})
+
+	root, err = s.Tree(26, allocated, true)
+	is.NoErr(err)
+	count := 0
+	root.Walk(func(node *TreeNode, depth int) {
+		count++
+	})
+	// the root plus the halves of the partial /22, /23, /24 and /25
+	is.Equal(count, 9)
+
+	_, err = s.Tree(20, nil, false)
+	is.True(errors.Is(err, ErrSplitToFewerBits))
+	_, err = s.Tree(24, []NamedPrefix{{Prefix: netip.MustParsePrefix("2001:db8::/32")}}, false)
+	is.True(errors.Is(err, ErrWrongFamily))
+}
+
+func TestRandomAddrs(t *testing.T) {
+	is := is.New(t)
+
+	for _, addrType := range RandomTypes {
+		addrs, err := RandomAddrsOfType(addrType, 50, RandomOptions{})
+		is.NoErr(err)
+		is.Equal(len(addrs), 50)
+		t.Log(addrType, addrs[:3])
+		for _, addr := range addrs {
+			switch addrType {
+			case PrivateType:
+				is.True(addr.IsPrivate())
+			case LinkLocalType:
+				is.True(addr.IsLinkLocalUnicast())
+			case MulticastType:
+				is.True(addr.IsMulticast())
+			case CGNATType:
+				is.True(netip.MustParsePrefix("100.64.0.0/10").Contains(addr))
+			case PublicType:
+				entry, ok := util.IP4SpecialPurpose(addr)
+				is.True(!ok || entry.GloballyReachable)
+				is.True(!addr.IsMulticast())
+			}
+		}
+	}
+	_, err := RandomAddrsOfType("bogus", 1, RandomOptions{})
+	is.True(errors.Is(err, ErrInvalidPrefix))
+
+	// every usable address in a /29 once
+	s, err := NewFromPrefix("192.0.2.0/29")
+	is.NoErr(err)
+	addrs, err := s.RandomAddrs(6, RandomOptions{Unique: true, Usable: true})
+	is.NoErr(err)
+	seen := make(map[netip.Addr]bool)
+	for _, addr := range addrs {
+		is.True(!seen[addr])
+		seen[addr] = true
+		is.True(addr != s.NetworkAddr() && addr != s.BroadcastAddr())
+	}
+	is.Equal(len(seen), 6)
+	_, err = s.RandomAddrs(7, RandomOptions{Unique: true, Usable: true})
+	t.Log(err)
+	is.True(errors.Is(err, ErrInvalidPrefix))
+
+	// exclusions are never picked
+	exclude := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/30"), netip.MustParsePrefix("192.0.2.6/32")}
+	addrs, err = s.RandomAddrs(40, RandomOptions{Exclude: exclude})
+	is.NoErr(err)
+	for _, addr := range addrs {
+		is.True(addr == netip.MustParseAddr("192.0.2.4") || addr == netip.MustParseAddr("192.0.2.5") || addr == netip.MustParseAddr("192.0.2.7"))
+	}
+
+	_, err = RandomAddrs([]netip.Prefix{netip.MustParsePrefix("2001:db8::/64")}, 1, RandomOptions{})
+	is.True(errors.Is(err, ErrWrongFamily))
+
+	// nothing left once everything is excluded
+	_, err = s.RandomAddrs(1, RandomOptions{Exclude: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/29")}})
+	t.Log(err)
+	is.True(errors.Is(err, ErrInvalidPrefix))
+}
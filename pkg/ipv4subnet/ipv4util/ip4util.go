@@ -3,15 +3,22 @@ package ipv4util
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
+	mathbits "math/bits"
 	"net"
 	"net/netip"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/imarsman/iptools/pkg/util"
 )
 
 // BitStr4 get bit string for IPV4 IP
 func BitStr4(ip netip.Addr, separator string) string {
+	if !ip.Is4() {
+		return ""
+	}
 	bytes := ip.As4()
 	list := []string{}
 
@@ -22,51 +29,51 @@ func BitStr4(ip netip.Addr, separator string) string {
 	return strings.Join(list, separator)
 }
 
+// binaryRE matches a 32 bit binary IPV4 address with or without dots between the octets
+var binaryRE = regexp.MustCompile(`^[01]{32}$|^[01]{8}\.[01]{8}\.[01]{8}\.[01]{8}$`)
+
+// decimalRE matches a value of only decimal digits
+var decimalRE = regexp.MustCompile(`^[0-9]+$`)
+
 // BinaryIP4StrToBytes convert a binary IP string for an IPV4 IP to a set of 4 bytes
 func BinaryIP4StrToBytes(ip string) (list []byte, err error) {
-	var parts []string
-	if !strings.Contains(ip, ".") {
-		if len(ip) != 32 {
-			err = fmt.Errorf("invalid binary ip %s", ip)
-			return
-		}
-		var matches bool
-		matches, err = regexp.MatchString(`[01]`, ip)
-		if err != nil {
-			return
-		}
-		if !matches {
-			err = fmt.Errorf("invalid binary ip %s", ip)
-			return
-		}
-		parts = []string{ip[0:8], ip[8:16], ip[16:24], ip[25:32]}
-	} else {
-		parts = strings.Split(ip, ".")
+	if !binaryRE.MatchString(ip) {
+		err = fmt.Errorf("invalid binary ip %s", ip)
+		return
 	}
-
-	for _, part := range parts {
-		var i int64
-		i, err = strconv.ParseInt(part, 2, 32)
+	ip = strings.ReplaceAll(ip, ".", "")
+	for i := 0; i < 32; i += 8 {
+		var b uint64
+		b, err = strconv.ParseUint(ip[i:i+8], 2, 8)
 		if err != nil {
 			return
 		}
-		list = append(list, byte(i))
+		list = append(list, byte(b))
 	}
 
 	return
 }
 
-// https://gist.github.com/ammario/649d4c0da650162efd404af23e25b86b
-func int2ip(ipInt uint32) (netip.Addr, error) {
-	ip := make(net.IP, 4)
-	binary.BigEndian.PutUint32(ip, ipInt)
-	addr, ok := netip.ParseAddr(ip.String())
+// AddrToUint32 get the integer value of an IPV4 address
+func AddrToUint32(addr netip.Addr) uint32 {
+	bytes := addr.As4()
+
+	return binary.BigEndian.Uint32(bytes[:])
+}
+
+// Uint32ToAddr get the IPV4 address for an integer value
+func Uint32ToAddr(value uint32) netip.Addr {
+	var bytes [4]byte
+	binary.BigEndian.PutUint32(bytes[:], value)
 
-	return addr, ok
+	return netip.AddrFrom4(bytes)
 }
 
 // WildCardMask get mask bits available for addressing for any IP address
 func WildCardMask(addr netip.Addr) netip.Addr {
+	if !addr.Is4() {
+		return netip.Addr{}
+	}
 	// return strings.Join(list, `.`)
 	bytes := addr.As4()
 	// var list = make([]string, 4, 4)
@@ -79,19 +86,18 @@ func WildCardMask(addr netip.Addr) netip.Addr {
 }
 
 // AddToAddr add count IPs to IP
-func AddToAddr(startIP netip.Addr, add int32) (addedIP netip.Addr, err error) {
-	if !startIP.Next().IsValid() {
-		err = fmt.Errorf("ip %v is already max", startIP)
+// An error is returned if the result would be past 255.255.255.255.
+func AddToAddr(startIP netip.Addr, add uint32) (addedIP netip.Addr, err error) {
+	if !startIP.Is4() {
+		err = fmt.Errorf("%w: ip %v is not an IPV4 address", util.ErrWrongFamily, startIP)
 		return
 	}
-	bytes := startIP.As4()
-	slice := bytes[:]
-	ipValue := binary.BigEndian.Uint32(slice)
-	ipValue += uint32(add)
-	addedIP, err = int2ip(ipValue)
-	if err != nil {
+	ipValue := uint64(AddrToUint32(startIP)) + uint64(add)
+	if ipValue > math.MaxUint32 {
+		err = fmt.Errorf("%w: adding %d to ip %v is past the last IPV4 address", util.ErrAddressOverflow, add, startIP)
 		return
 	}
+	addedIP = Uint32ToAddr(uint32(ipValue))
 
 	return
 }
@@ -116,6 +122,67 @@ func Arpa(ip netip.Addr) string {
 	return fmt.Sprintf("%s.%s", strings.Join(parts, "."), "in-addr.arpa")
 }
 
+// FromArpa get the address or prefix for a full or partial in-addr.arpa name
+// A name with fewer than four octets is a prefix, so 2.0.192.in-addr.arpa is
+// 192.0.2.0/24. RFC 2317 classless names such as 64/26.2.0.192.in-addr.arpa and
+// the host names under them are understood.
+func FromArpa(name string) (prefix netip.Prefix, err error) {
+	fqdn := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if fqdn != "in-addr.arpa" && !strings.HasSuffix(fqdn, ".in-addr.arpa") {
+		err = fmt.Errorf("%w: %q is not an in-addr.arpa name", util.ErrInvalidPrefix, name)
+		return
+	}
+	labels := strings.Split(strings.TrimSuffix(fqdn, "in-addr.arpa"), ".")
+	labels = labels[:len(labels)-1]
+	reverse(labels)
+
+	var octets [4]byte
+	// the prefix set by an RFC 2317 label such as 64/26
+	var classless netip.Prefix
+	count := 0
+	for i, label := range labels {
+		if count == 3 && classless == (netip.Prefix{}) && strings.ContainsAny(label, "/-") {
+			var first, bits uint64
+			parts := strings.FieldsFunc(label, func(r rune) bool { return r == '/' || r == '-' })
+			if len(parts) == 2 {
+				first, err = strconv.ParseUint(parts[0], 10, 8)
+				if err == nil {
+					bits, err = strconv.ParseUint(parts[1], 10, 8)
+				}
+			}
+			if len(parts) != 2 || err != nil || bits < 25 || bits > 32 {
+				err = fmt.Errorf("%w: %q has an invalid classless label %q", util.ErrInvalidPrefix, name, label)
+				return
+			}
+			octets[3] = byte(first)
+			classless = netip.PrefixFrom(netip.AddrFrom4(octets), int(bits))
+			if classless.Masked() != classless {
+				err = fmt.Errorf("%w: %q has classless label %q that is not aligned", util.ErrInvalidPrefix, name, label)
+				return
+			}
+			if i == len(labels)-1 {
+				prefix = classless
+				return
+			}
+			continue
+		}
+		var value uint64
+		value, err = strconv.ParseUint(label, 10, 8)
+		if err != nil || count == 4 || len(label) > 1 && label[0] == '0' {
+			err = fmt.Errorf("%w: %q has an invalid octet %q", util.ErrInvalidPrefix, name, label)
+			return
+		}
+		octets[count] = byte(value)
+		count++
+	}
+	prefix = netip.PrefixFrom(netip.AddrFrom4(octets), count*8)
+	if classless.IsValid() && !classless.Contains(prefix.Addr()) {
+		err = fmt.Errorf("%w: %q is outside of %s", util.ErrInvalidPrefix, name, classless)
+	}
+
+	return
+}
+
 // IPToHexStr convert an IP4 address to a hex string
 func IPToHexStr(ip netip.Addr) string {
 	if !ip.Is4() {
@@ -128,3 +195,130 @@ func IPToHexStr(ip netip.Addr) string {
 
 	return fmt.Sprintf("0x%X", ipValue)
 }
+
+// ParseAddr parse an address written in any of the notations seen in configs and tickets
+// As well as the usual forms an IPV4 address can be a decimal integer such as
+// 167772160, hex such as 0x0a000000 or 32 bits of binary with or without dots
+// between the octets.
+func ParseAddr(value string) (addr netip.Addr, err error) {
+	value = strings.TrimSpace(value)
+	addr, err = netip.ParseAddr(value)
+	if err == nil {
+		return
+	}
+	var n uint64
+	switch {
+	case binaryRE.MatchString(strings.TrimPrefix(value, "0b")):
+		var bytes []byte
+		bytes, err = BinaryIP4StrToBytes(strings.TrimPrefix(value, "0b"))
+		if err != nil {
+			break
+		}
+		addr = netip.AddrFrom4([4]byte(bytes))
+		return
+	case strings.HasPrefix(strings.ToLower(value), "0x"):
+		n, err = strconv.ParseUint(value[2:], 16, 32)
+	default:
+		n, err = strconv.ParseUint(value, 10, 32)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %q is not an address", util.ErrInvalidPrefix, value)
+		return
+	}
+	addr = Uint32ToAddr(uint32(n))
+
+	return
+}
+
+// ParseMask get the prefix bits for a mask in any of the notations seen in configs
+// The mask can be a bit count such as 24, a netmask such as 255.255.255.0 or a
+// Cisco style wildcard mask such as 0.0.0.255, with netmasks and wildcard masks
+// dotted, in hex or in binary. A mask of only decimal digits is always a bit count,
+// so a mistyped length such as 63 is rejected rather than read as a wildcard mask.
+// A value that is a valid netmask is taken as one, so 0.0.0.0 is /0 and not a host
+// wildcard. Masks with ones and zeros mixed are rejected.
+func ParseMask(value string) (bits int, err error) {
+	value = strings.TrimSpace(value)
+	if decimalRE.MatchString(value) && !binaryRE.MatchString(value) {
+		bits, err = strconv.Atoi(value)
+		if err != nil || bits < 0 || bits > 32 {
+			err = fmt.Errorf("%w: %q is not a bit count from 0 to 32", util.ErrInvalidPrefix, value)
+		}
+		return
+	}
+	addr, err := ParseAddr(value)
+	if err != nil || !addr.Is4() {
+		err = fmt.Errorf("%w: %q is not a mask", util.ErrInvalidPrefix, value)
+		return
+	}
+	mask := AddrToUint32(addr)
+	switch {
+	case isNetmask(mask):
+		bits = mathbits.OnesCount32(mask)
+	case isNetmask(^mask):
+		bits = mathbits.OnesCount32(^mask)
+	default:
+		err = fmt.Errorf("%w: %q is not a bit count from 0 to 32 or a contiguous netmask or wildcard mask", util.ErrInvalidPrefix, value)
+	}
+
+	return
+}
+
+// isNetmask check that a mask is a run of ones followed by a run of zeros
+func isNetmask(mask uint32) bool {
+	inverse := ^mask
+
+	return inverse&(inverse+1) == 0
+}
+
+// SplitPrefix split a prefix into its address and mask
+// The mask follows a slash as in 10.0.0.0/24 or whitespace as in router configs such
+// as 10.0.0.0 255.255.255.0. The mask is empty for an address alone.
+func SplitPrefix(value string) (addr, mask string) {
+	value = strings.TrimSpace(value)
+	if i := strings.Index(value, "/"); i >= 0 {
+		return strings.TrimSpace(value[:i]), strings.TrimSpace(value[i+1:])
+	}
+	fields := strings.Fields(value)
+	if len(fields) == 2 {
+		return fields[0], fields[1]
+	}
+
+	return value, ""
+}
+
+// ParsePrefix parse a prefix with the address and mask in any notation
+// An address with no mask is a full length prefix. IPV6 prefixes are parsed as
+// usual with the mask as a bit count.
+func ParsePrefix(value string) (prefix netip.Prefix, err error) {
+	prefix, err = netip.ParsePrefix(strings.TrimSpace(value))
+	if err == nil {
+		return
+	}
+	addrStr, maskStr := SplitPrefix(value)
+	addr, err := ParseAddr(addrStr)
+	if err != nil {
+		return
+	}
+	bits := addr.BitLen()
+	if maskStr != "" {
+		if addr.Is4() {
+			bits, err = ParseMask(maskStr)
+			if err != nil {
+				return
+			}
+		} else {
+			bits, err = strconv.Atoi(maskStr)
+			if err != nil {
+				err = fmt.Errorf("%w: %q is not a bit count", util.ErrInvalidPrefix, maskStr)
+				return
+			}
+		}
+	}
+	prefix = netip.PrefixFrom(addr, bits)
+	if !prefix.IsValid() {
+		err = fmt.Errorf("%w: %q has an invalid mask", util.ErrInvalidPrefix, value)
+	}
+
+	return
+}
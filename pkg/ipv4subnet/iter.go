@@ -0,0 +1,124 @@
+package ipv4subnet
+
+import (
+	"fmt"
+	"iter"
+	"net/netip"
+
+	ip4util "github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+)
+
+// subnetFromPrefix make a subnet for an IPV4 prefix without parsing strings
+func subnetFromPrefix(prefix netip.Prefix) *Subnet {
+	subnet := new(Subnet)
+	subnet.prefix = prefix.Masked()
+	subnet.netMaskPrefix = netip.PrefixFrom(netip.AddrFrom4([4]byte{255, 255, 255, 255}), prefix.Bits()).Masked()
+
+	return subnet
+}
+
+// blockSize number of addresses in a block with prefix bits
+func blockSize(bits int) uint64 {
+	return uint64(1) << (32 - bits)
+}
+
+// IPsSeq iterate over every IP in the subnet
+func (s *Subnet) IPsSeq() iter.Seq[netip.Addr] {
+	start := uint64(ip4util.AddrToUint32(s.IP()))
+	size := blockSize(s.Prefix().Bits())
+
+	return func(yield func(netip.Addr) bool) {
+		for i := uint64(0); i < size; i++ {
+			if !yield(ip4util.Uint32ToAddr(uint32(start + i))) {
+				return
+			}
+		}
+	}
+}
+
+// UsableIPsSeq iterate over the IPs in the subnet other than network and broadcast addresses
+// Every address in a /31 or /32 is usable.
+func (s *Subnet) UsableIPsSeq() iter.Seq[netip.Addr] {
+	if s.Prefix().Bits() >= 31 {
+		return s.IPsSeq()
+	}
+	start := uint64(ip4util.AddrToUint32(s.IP()))
+	size := blockSize(s.Prefix().Bits())
+
+	return func(yield func(netip.Addr) bool) {
+		for i := uint64(1); i+1 < size; i++ {
+			if !yield(ip4util.Uint32ToAddr(uint32(start + i))) {
+				return
+			}
+		}
+	}
+}
+
+// IPRangesSeq iterate over the set of equally sized ranges for subnet
+func (s *Subnet) IPRangesSeq() iter.Seq[Range] {
+	seq, _ := s.ipRangesSeq(s)
+
+	return seq
+}
+
+// SecondaryIPRangesSeq iterate over the ranges in the context of parent subnet
+func (s *Subnet) SecondaryIPRangesSeq(secondarySubnet *Subnet) (iter.Seq[Range], error) {
+	return s.ipRangesSeq(secondarySubnet)
+}
+
+// SubnetsSeq iterate over the set of equally sized subnets for subnet
+func (s *Subnet) SubnetsSeq() iter.Seq[*Subnet] {
+	seq, _ := s.subnetsSeq(s)
+
+	return seq
+}
+
+// SecondarySubnetsSeq iterate over the subnets in the context of parent subnet
+func (s *Subnet) SecondarySubnetsSeq(secondarySubnet *Subnet) (iter.Seq[*Subnet], error) {
+	return s.subnetsSeq(secondarySubnet)
+}
+
+// subnetsSeq iterate over the subnets splitting by secondary subnet (can be self)
+func (s *Subnet) subnetsSeq(secondarySubnet *Subnet) (seq iter.Seq[*Subnet], err error) {
+	ranges, err := s.ipRangesSeq(secondarySubnet)
+	if err != nil {
+		return
+	}
+	bits := secondarySubnet.Prefix().Bits()
+
+	seq = func(yield func(*Subnet) bool) {
+		for r := range ranges {
+			if !yield(subnetFromPrefix(netip.PrefixFrom(r.First(), bits))) {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// ipRangesSeq iterate over the ranges for a subnet splitting by secondary subnet (can be self)
+func (s *Subnet) ipRangesSeq(secondarySubnet *Subnet) (seq iter.Seq[Range], err error) {
+	// Can't subdivide to smaller prefixed subnet
+	if secondarySubnet.Prefix().Bits() < s.Prefix().Bits() {
+		err = fmt.Errorf("%w: %s split to /%d", ErrSplitToFewerBits, s.CIDR(), secondarySubnet.Prefix().Bits())
+		return
+	}
+	// the networks for the subnet are those of its size in its class block
+	classPrefix := netip.PrefixFrom(s.IP(), s.startClassBits()).Masked()
+	start := uint64(ip4util.AddrToUint32(classPrefix.Addr()))
+	size := blockSize(secondarySubnet.Prefix().Bits())
+	count := uint64(s.Networks()) << (secondarySubnet.Prefix().Bits() - s.Prefix().Bits())
+
+	seq = func(yield func(Range) bool) {
+		for i := uint64(0); i < count; i++ {
+			first := start + i*size
+			last := first + size - 1
+			if !yield(NewRange(ip4util.Uint32ToAddr(uint32(first)), ip4util.Uint32ToAddr(uint32(last)))) {
+				return
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,85 @@
+package ipv4subnet
+
+import (
+	"net/netip"
+	"sort"
+)
+
+const (
+	// DuplicateRelation the prefixes are the same
+	DuplicateRelation = "duplicate"
+	// NestedRelation the second prefix is inside the first
+	NestedRelation = "nested"
+)
+
+// NamedPrefix an IPV4 or IPV6 prefix with an optional name
+type NamedPrefix struct {
+	Name   string
+	Prefix netip.Prefix
+}
+
+// Overlap a pair of prefixes sharing addresses
+// First is always the larger of the two prefixes so Shared is the range of Second.
+type Overlap struct {
+	First    NamedPrefix
+	Second   NamedPrefix
+	Relation string
+	Shared   Range
+}
+
+// Overlaps find every pair of prefixes that overlap
+// Prefixes can only overlap by being equal or one being inside the other, so
+// each pair is reported as a duplicate or as nested.
+func Overlaps(prefixes []NamedPrefix) (overlaps []Overlap) {
+	sorted := make([]NamedPrefix, 0, len(prefixes))
+	for _, p := range prefixes {
+		if !p.Prefix.IsValid() {
+			continue
+		}
+		p.Prefix = p.Prefix.Masked()
+		sorted = append(sorted, p)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Prefix, sorted[j].Prefix
+		if a.Addr().BitLen() != b.Addr().BitLen() {
+			return a.Addr().BitLen() < b.Addr().BitLen()
+		}
+		if a.Addr() != b.Addr() {
+			return a.Addr().Less(b.Addr())
+		}
+		return a.Bits() < b.Bits()
+	})
+
+	for i, first := range sorted {
+		last := prefixLast(first.Prefix)
+		for _, second := range sorted[i+1:] {
+			// sorted by start so nothing further on can be inside first
+			if second.Prefix.Addr().BitLen() != first.Prefix.Addr().BitLen() ||
+				last.Less(second.Prefix.Addr()) {
+				break
+			}
+			relation := NestedRelation
+			if second.Prefix == first.Prefix {
+				relation = DuplicateRelation
+			}
+			overlaps = append(overlaps, Overlap{
+				First:    first,
+				Second:   second,
+				Relation: relation,
+				Shared:   NewRangeFromPrefix(second.Prefix),
+			})
+		}
+	}
+
+	return
+}
+
+// SubnetOverlaps find every pair of subnets that overlap using subnet names
+func SubnetOverlaps(subnets []*Subnet) []Overlap {
+	prefixes := []NamedPrefix{}
+	for _, s := range subnets {
+		prefixes = append(prefixes, NamedPrefix{Name: s.Name(), Prefix: s.Prefix()})
+	}
+
+	return Overlaps(prefixes)
+}
@@ -0,0 +1,171 @@
+package ipv4subnet
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+const (
+	// PrivateType RFC 1918 private-use addresses
+	PrivateType = "private"
+	// CGNATType RFC 6598 shared address space used for carrier grade NAT
+	CGNATType = "cgnat"
+	// PublicType globally reachable unicast addresses that are not bogons
+	PublicType = "public"
+	// LinkLocalType RFC 3927 link local addresses
+	LinkLocalType = "link-local"
+	// MulticastType multicast addresses
+	MulticastType = "multicast"
+	// DocumentationType the RFC 5737 TEST-NET blocks
+	DocumentationType = "documentation"
+)
+
+// RandomTypes the types of address that can be generated
+var RandomTypes = []string{PrivateType, CGNATType, PublicType, LinkLocalType, MulticastType, DocumentationType}
+
+// randomTypePrefixes the blocks addresses of each type are taken from
+// Public addresses are taken from all of the address space less the bogons.
+var randomTypePrefixes = map[string][]string{
+	PrivateType:       {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+	CGNATType:         {"100.64.0.0/10"},
+	PublicType:        {"0.0.0.0/0"},
+	LinkLocalType:     {"169.254.0.0/16"},
+	MulticastType:     {"224.0.0.0/4"},
+	DocumentationType: {"192.0.2.0/24", "198.51.100.0/24", "203.0.113.0/24"},
+}
+
+// RandomOptions how random addresses are picked
+// With Unique no address is returned twice. With Usable the network and broadcast
+// addresses of the prefixes addresses are taken from are left out, other than for
+// /31 and /32 prefixes. Addresses in Exclude are never returned.
+type RandomOptions struct {
+	Unique  bool
+	Usable  bool
+	Exclude []netip.Prefix
+}
+
+// RandomAddrsOfType get count random addresses of a type such as private or public
+func RandomAddrsOfType(addrType string, count int, options RandomOptions) (addrs []netip.Addr, err error) {
+	prefixStrs, ok := randomTypePrefixes[addrType]
+	if !ok {
+		err = fmt.Errorf("%w: unknown address type %s, expected one of %v", ErrInvalidPrefix, addrType, RandomTypes)
+		return
+	}
+	prefixes := []netip.Prefix{}
+	for _, prefixStr := range prefixStrs {
+		prefixes = append(prefixes, netip.MustParsePrefix(prefixStr))
+	}
+	if addrType == PublicType {
+		options.Exclude = append(options.Exclude, bogons()...)
+	}
+
+	return RandomAddrs(prefixes, count, options)
+}
+
+// RandomAddrs get count random addresses spread evenly over a list of IPV4 prefixes
+func RandomAddrs(prefixes []netip.Prefix, count int, options RandomOptions) (addrs []netip.Addr, err error) {
+	ranges := []Range{}
+	var total uint64
+	for _, prefix := range prefixes {
+		prefix = util.UnmapPrefix(prefix)
+		if !prefix.Addr().Is4() {
+			err = fmt.Errorf("%w: %s is not an IPV4 prefix", ErrWrongFamily, prefix)
+			return
+		}
+		prefix = prefix.Masked()
+		exclude := options.Exclude
+		if options.Usable && prefix.Bits() < 31 {
+			exclude = append(exclude[:len(exclude):len(exclude)],
+				netip.PrefixFrom(prefix.Addr(), 32),
+				netip.PrefixFrom(prefixLast(prefix), 32),
+			)
+		}
+		var free []Range
+		free, err = freeRanges(prefix, exclude)
+		if err != nil {
+			return
+		}
+		for _, r := range free {
+			ranges = append(ranges, r)
+			total += rangeSize(r)
+		}
+	}
+	if total == 0 {
+		err = fmt.Errorf("%w: no addresses to pick from", ErrInvalidPrefix)
+		return
+	}
+	if options.Unique && uint64(count) > total {
+		err = fmt.Errorf("%w: %d unique addresses asked for but there are only %d to pick from", ErrInvalidPrefix, count, total)
+		return
+	}
+
+	seen := make(map[netip.Addr]bool)
+	for len(addrs) < count {
+		var n uint64
+		n, err = randUint64(total)
+		if err != nil {
+			return
+		}
+		addr := nthAddr(ranges, n)
+		if options.Unique {
+			if seen[addr] {
+				continue
+			}
+			seen[addr] = true
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return
+}
+
+// RandomAddrs get count random addresses in the subnet
+func (s *Subnet) RandomAddrs(count int, options RandomOptions) (addrs []netip.Addr, err error) {
+	return RandomAddrs([]netip.Prefix{s.Prefix()}, count, options)
+}
+
+// bogons get the special-purpose blocks that are not globally reachable along with multicast
+func bogons() (prefixes []netip.Prefix) {
+	for _, entry := range util.IP4SpecialPurposeRegistry() {
+		if !entry.GloballyReachable {
+			prefixes = append(prefixes, entry.Prefix)
+		}
+	}
+	prefixes = append(prefixes, netip.MustParsePrefix(randomTypePrefixes[MulticastType][0]))
+
+	return
+}
+
+// rangeSize get the number of addresses in an IPV4 range
+func rangeSize(r Range) uint64 {
+	return uint64(ipv4util.AddrToUint32(r.Last())-ipv4util.AddrToUint32(r.First())) + 1
+}
+
+// nthAddr get the address n places into a list of ranges taken one after another
+func nthAddr(ranges []Range, n uint64) netip.Addr {
+	for _, r := range ranges {
+		size := rangeSize(r)
+		if n < size {
+			return ipv4util.Uint32ToAddr(ipv4util.AddrToUint32(r.First()) + uint32(n))
+		}
+		n -= size
+	}
+
+	return netip.Addr{}
+}
+
+// randUint64 use crypto/rand to get a number from 0 up to but not including max
+func randUint64(max uint64) (n uint64, err error) {
+	bigInt, err := rand.Int(rand.Reader, new(big.Int).SetUint64(max))
+	if err != nil {
+		return
+	}
+	n = bigInt.Uint64()
+
+	return
+}
@@ -0,0 +1,123 @@
+package ipv4subnet
+
+import (
+	"fmt"
+	"math/bits"
+	"net/netip"
+
+	ip4util "github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+)
+
+const (
+	// EqualRelation the subnets are the same
+	EqualRelation = "equal"
+	// ContainsRelation the first subnet contains the second
+	ContainsRelation = "contains"
+	// InsideRelation the first subnet is inside the second
+	InsideRelation = "inside"
+	// SiblingRelation the subnets are the two halves of the same supernet
+	SiblingRelation = "sibling"
+	// AdjacentRelation the subnets do not overlap but one starts right after the other ends
+	AdjacentRelation = "adjacent"
+	// DisjointRelation the subnets share no addresses and do not touch
+	DisjointRelation = "disjoint"
+)
+
+// Contains is the address in the subnet
+func (s *Subnet) Contains(addr netip.Addr) bool {
+	return s.Prefix().Contains(addr.Unmap())
+}
+
+// ContainsSubnet are all of the addresses in other in the subnet
+func (s *Subnet) ContainsSubnet(other *Subnet) bool {
+	return other.Prefix().Bits() >= s.Prefix().Bits() && s.Contains(other.IP())
+}
+
+// Overlaps do the subnets share any addresses
+func (s *Subnet) Overlaps(other *Subnet) bool {
+	return s.Prefix().Overlaps(other.Prefix())
+}
+
+// Supernet get the subnet with fewer bits that contains the subnet
+func (s *Subnet) Supernet(bits int) (subnet *Subnet, err error) {
+	if bits < 0 || bits > s.Prefix().Bits() {
+		err = fmt.Errorf("%w: supernet of %s must have 0 to %d bits, not %d",
+			ErrInvalidPrefix, s.CIDR(), s.Prefix().Bits(), bits)
+		return
+	}
+	subnet = subnetFromPrefix(netip.PrefixFrom(s.IP(), bits))
+
+	return
+}
+
+// Sibling get the other half of the supernet one bit shorter than the subnet
+func (s *Subnet) Sibling() (subnet *Subnet, err error) {
+	prefixBits := s.Prefix().Bits()
+	if prefixBits == 0 {
+		err = fmt.Errorf("%w: %s has no sibling", ErrInvalidPrefix, s.CIDR())
+		return
+	}
+	addr := ip4util.AddrToUint32(s.IP()) ^ uint32(blockSize(prefixBits))
+	subnet = subnetFromPrefix(netip.PrefixFrom(ip4util.Uint32ToAddr(addr), prefixBits))
+
+	return
+}
+
+// Next get the block of the same size right after the subnet
+func (s *Subnet) Next() (subnet *Subnet, err error) {
+	size := blockSize(s.Prefix().Bits())
+	next := uint64(ip4util.AddrToUint32(s.IP())) + size
+	if next > uint64(^uint32(0)) {
+		err = fmt.Errorf("%w: no block after %s", ErrAddressOverflow, s.CIDR())
+		return
+	}
+	subnet = subnetFromPrefix(netip.PrefixFrom(ip4util.Uint32ToAddr(uint32(next)), s.Prefix().Bits()))
+
+	return
+}
+
+// Previous get the block of the same size right before the subnet
+func (s *Subnet) Previous() (subnet *Subnet, err error) {
+	size := blockSize(s.Prefix().Bits())
+	start := uint64(ip4util.AddrToUint32(s.IP()))
+	if start < size {
+		err = fmt.Errorf("%w: no block before %s", ErrAddressOverflow, s.CIDR())
+		return
+	}
+	subnet = subnetFromPrefix(netip.PrefixFrom(ip4util.Uint32ToAddr(uint32(start-size)), s.Prefix().Bits()))
+
+	return
+}
+
+// CommonPrefixBits get the number of leading bits the subnets have in common
+// The result is never more than the bits of the shorter of the two prefixes.
+func (s *Subnet) CommonPrefixBits(other *Subnet) int {
+	common := bits.LeadingZeros32(ip4util.AddrToUint32(s.IP()) ^ ip4util.AddrToUint32(other.IP()))
+
+	return min(common, s.Prefix().Bits(), other.Prefix().Bits())
+}
+
+// CommonSupernet get the smallest subnet containing both subnets
+func (s *Subnet) CommonSupernet(other *Subnet) *Subnet {
+	return subnetFromPrefix(netip.PrefixFrom(s.IP(), s.CommonPrefixBits(other)))
+}
+
+// Relate get how the subnet relates to other
+func (s *Subnet) Relate(other *Subnet) string {
+	switch {
+	case s.Prefix() == other.Prefix():
+		return EqualRelation
+	case s.ContainsSubnet(other):
+		return ContainsRelation
+	case other.ContainsSubnet(s):
+		return InsideRelation
+	}
+	if sibling, err := s.Sibling(); err == nil && sibling.Prefix() == other.Prefix() {
+		return SiblingRelation
+	}
+	if s.Last().Next() == other.IP() || other.Last().Next() == s.IP() {
+		return AdjacentRelation
+	}
+
+	return DisjointRelation
+}
@@ -0,0 +1,101 @@
+package ipv4subnet
+
+import (
+	"net/netip"
+	"sort"
+
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// prefixLast get the last address in a prefix
+func prefixLast(prefix netip.Prefix) netip.Addr {
+	prefix = prefix.Masked()
+	bytes := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+
+	return addr
+}
+
+// rangePrefixes get the minimal set of prefixes covering first to last inclusive
+// Both addresses must be of the same family and first must not be after last.
+func rangePrefixes(first, last netip.Addr) (prefixes []netip.Prefix) {
+	for {
+		bits := first.BitLen()
+		// widen the prefix while it stays aligned on first and inside the range
+		for bits > 0 {
+			wider := netip.PrefixFrom(first, bits-1).Masked()
+			if wider.Addr() != first || prefixLast(wider).Compare(last) > 0 {
+				break
+			}
+			bits--
+		}
+		prefix := netip.PrefixFrom(first, bits)
+		prefixes = append(prefixes, prefix)
+
+		end := prefixLast(prefix)
+		if end.Compare(last) >= 0 {
+			break
+		}
+		first = end.Next()
+	}
+
+	return
+}
+
+// Summarize collapse a list of prefixes into the minimal set of prefixes
+// covering the same addresses
+// Nested prefixes are dropped and adjacent prefixes are merged. IPV4 prefixes
+// are returned before IPV6 prefixes, each in address order.
+func Summarize(prefixes []netip.Prefix) (summary []netip.Prefix) {
+	ranges := []Range{}
+	for _, p := range prefixes {
+		if !p.IsValid() {
+			continue
+		}
+		// treat IPV4 mapped IPV6 prefixes as IPV4
+		p = util.UnmapPrefix(p)
+		p = p.Masked()
+		ranges = append(ranges, NewRangeFromPrefix(p))
+	}
+
+	for _, r := range MergeRanges(ranges) {
+		summary = append(summary, rangePrefixes(r.first, r.last)...)
+	}
+
+	return
+}
+
+// MergeRanges sort ranges and merge those that overlap or are adjacent
+// IPV4 ranges are sorted before IPV6 ranges.
+func MergeRanges(ranges []Range) (merged []Range) {
+	sorted := make([]Range, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].first.BitLen() != sorted[j].first.BitLen() {
+			return sorted[i].first.BitLen() < sorted[j].first.BitLen()
+		}
+		return sorted[i].first.Less(sorted[j].first)
+	})
+
+	for _, r := range sorted {
+		if len(merged) > 0 {
+			current := &merged[len(merged)-1]
+			if current.first.BitLen() == r.first.BitLen() {
+				next := current.last.Next()
+				// an invalid next means current runs to the end of the address space
+				if !next.IsValid() || r.first.Compare(next) <= 0 {
+					if r.last.Compare(current.last) > 0 {
+						current.last = r.last
+					}
+					continue
+				}
+			}
+		}
+		merged = append(merged, r)
+	}
+
+	return
+}
@@ -0,0 +1,99 @@
+package ipv4subnet
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+const (
+	// FreeState no address in the subnet is allocated
+	FreeState = "free"
+	// AllocatedState the subnet is inside an allocation
+	AllocatedState = "allocated"
+	// PartialState some but not all of the subnet is allocated
+	PartialState = "partial"
+)
+
+// maxTreeLevels the most levels of halving a tree can have
+const maxTreeLevels = 16
+
+// TreeNode a subnet in a hierarchy of halvings along with the two halves it splits into
+// Name is the name of the allocation the subnet is inside if it is allocated.
+type TreeNode struct {
+	Subnet   *Subnet
+	State    string
+	Name     string
+	Children []*TreeNode
+}
+
+// Tree get the hierarchy of halvings of the subnet down to subnets with secondary bits
+// Each node is marked allocated, partially allocated or free using allocated. With
+// compact nodes that are entirely free or allocated are not split any further.
+func (s *Subnet) Tree(secondaryBits int, allocated []NamedPrefix, compact bool) (root *TreeNode, err error) {
+	if secondaryBits < s.Prefix().Bits() {
+		err = fmt.Errorf("%w: %s split to /%d", ErrSplitToFewerBits, s.CIDR(), secondaryBits)
+		return
+	}
+	if secondaryBits > 32 {
+		err = fmt.Errorf("%w: /%d is longer than 32 bits", ErrInvalidPrefix, secondaryBits)
+		return
+	}
+	if secondaryBits-s.Prefix().Bits() > maxTreeLevels {
+		err = fmt.Errorf("%s split to /%d is more than %d levels", s.CIDR(), secondaryBits, maxTreeLevels)
+		return
+	}
+	prefixes := []NamedPrefix{}
+	for _, a := range allocated {
+		prefix := a.Prefix
+		prefix = util.UnmapPrefix(prefix)
+		if !prefix.Addr().Is4() {
+			err = fmt.Errorf("%w: allocation %s is not an IPV4 prefix", ErrWrongFamily, a.Prefix)
+			return
+		}
+		prefixes = append(prefixes, NamedPrefix{Name: a.Name, Prefix: prefix.Masked()})
+	}
+	root = treeNode(s.Prefix(), secondaryBits, prefixes, compact)
+
+	return
+}
+
+// treeNode get the node for a prefix and its halves down to prefixes with bits
+func treeNode(prefix netip.Prefix, bits int, allocated []NamedPrefix, compact bool) *TreeNode {
+	node := &TreeNode{Subnet: subnetFromPrefix(prefix), State: FreeState}
+	for _, a := range allocated {
+		if a.Prefix.Bits() <= prefix.Bits() && a.Prefix.Contains(prefix.Addr()) {
+			node.State, node.Name = AllocatedState, a.Name
+			break
+		}
+		if a.Prefix.Overlaps(prefix) {
+			node.State = PartialState
+		}
+	}
+	if prefix.Bits() >= bits || compact && node.State != PartialState {
+		return node
+	}
+
+	left := netip.PrefixFrom(prefix.Addr(), prefix.Bits()+1)
+	right := netip.PrefixFrom(prefixLast(prefix), prefix.Bits()+1).Masked()
+	node.Children = []*TreeNode{
+		treeNode(left, bits, allocated, compact),
+		treeNode(right, bits, allocated, compact),
+	}
+
+	return node
+}
+
+// Walk call fn for the node and each node below it in order along with its depth
+func (n *TreeNode) Walk(fn func(node *TreeNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+// walk call fn for the node and the nodes below it
+func (n *TreeNode) walk(fn func(node *TreeNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
@@ -0,0 +1,109 @@
+package ipv4subnet
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	ip4util "github.com/imarsman/iptools/pkg/ipv4subnet/ipv4util"
+)
+
+// Requirement a named number of hosts needed in a VLSM allocation
+type Requirement struct {
+	Name  string
+	Hosts int64
+}
+
+// ParseRequirement parse a requirement in the form name:hosts
+func ParseRequirement(value string) (requirement Requirement, err error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		err = fmt.Errorf("%w: invalid requirement %q, expected name:hosts", ErrInvalidPrefix, value)
+		return
+	}
+	hosts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || hosts < 1 {
+		err = fmt.Errorf("%w: invalid host count in requirement %q", ErrInvalidPrefix, value)
+		return
+	}
+	requirement = Requirement{Name: parts[0], Hosts: hosts}
+
+	return
+}
+
+// requirementBits get the prefix bits for the smallest block holding hosts
+// plus network and broadcast addresses
+func requirementBits(hosts int64) int {
+	needed := uint64(hosts) + 2
+	hostBits := bits.Len64(needed - 1)
+
+	return 32 - hostBits
+}
+
+// VLSM allocate variable sized subnets for requirements from the subnet
+// Requirements are packed largest first so every allocation stays aligned. The
+// allocations are named after their requirements and free holds the maximal
+// subnets left over.
+func (s *Subnet) VLSM(requirements []Requirement) (allocated []*Subnet, free []*Subnet, err error) {
+	sorted := make([]Requirement, len(requirements))
+	copy(sorted, requirements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Hosts > sorted[j].Hosts
+	})
+
+	size := uint64(1) << (32 - s.Prefix().Bits())
+
+	var needed uint64
+	names := make(map[string]bool)
+	for _, r := range sorted {
+		if names[r.Name] {
+			err = fmt.Errorf("%w: duplicate requirement name %s", ErrInvalidPrefix, r.Name)
+			return
+		}
+		names[r.Name] = true
+		if r.Hosts < 1 {
+			err = fmt.Errorf("%w: requirement %s needs at least one host", ErrInvalidPrefix, r.Name)
+			return
+		}
+		if requirementBits(r.Hosts) < s.Prefix().Bits() {
+			err = fmt.Errorf("%w: requirement %s for %d hosts does not fit in %s", ErrInvalidPrefix, r.Name, r.Hosts, s.CIDR())
+			return
+		}
+		needed += uint64(1) << (32 - requirementBits(r.Hosts))
+	}
+	if needed > size {
+		err = fmt.Errorf("%w: requirements need %d addresses but %s has %d", ErrInvalidPrefix, needed, s.CIDR(), size)
+		return
+	}
+
+	start := uint64(ip4util.AddrToUint32(s.IP()))
+	offset := uint64(0)
+	for _, r := range sorted {
+		prefixBits := requirementBits(r.Hosts)
+		var subnet *Subnet
+		subnet, err = NewFromAddrAndBits(ip4util.Uint32ToAddr(uint32(start+offset)).String(), prefixBits)
+		if err != nil {
+			return
+		}
+		subnet.SetName(r.Name)
+		allocated = append(allocated, subnet)
+		offset += uint64(1) << (32 - prefixBits)
+	}
+
+	if offset < size {
+		first := ip4util.Uint32ToAddr(uint32(start + offset))
+		last := ip4util.Uint32ToAddr(uint32(start + size - 1))
+		for _, p := range rangePrefixes(first, last) {
+			var subnet *Subnet
+			subnet, err = NewFromPrefix(p.String())
+			if err != nil {
+				return
+			}
+			free = append(free, subnet)
+		}
+	}
+
+	return
+}
@@ -1,6 +1,7 @@
 package ipv6
 
 import (
+	"errors"
 	"net"
 	"net/netip"
 	"strconv"
@@ -107,7 +108,9 @@ func TestMakeMacAddress(t *testing.T) {
 }
 
 func TestRandomSubnet(t *testing.T) {
-	randSubnet := addrRandSubnetID()
+	is := is.New(t)
+	randSubnet, err := addrRandSubnetID()
+	is.NoErr(err)
 
 	t.Log(strconv.FormatInt(int64(randSubnet), 16))
 }
@@ -126,3 +129,68 @@ func TestBits(t *testing.T) {
 
 	t.Log(converted)
 }
+
+func TestAddrTypePrefix(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		addr   string
+		prefix string
+	}{
+		{"2001:db8::1", "2000::/3"},
+		{"fd12:3456::1", "fc00::/7"},
+		{"fe80::1", "fe80::/10"},
+		{"ff02::1", "ff00::/8"},
+		{"::1", "::1/128"},
+		{"::", "::/128"},
+	}
+	for _, test := range tests {
+		prefix := AddrTypePrefix(netip.MustParseAddr(test.addr))
+		t.Log(test.addr, prefix)
+		is.Equal(prefix.String(), test.prefix)
+	}
+
+	// the global ID of a unique local address follows the 8 bit fd prefix
+	globalID, err := AddrGlobalID(netip.MustParseAddr("fd12:3456:789a::1"))
+	is.NoErr(err)
+	t.Log("global ID", globalID)
+	is.Equal(globalID, "12:3456:789a")
+
+	_, err = bitRangeHex(netip.MustParseAddr("2001:db8::1"), 0, 65)
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+}
+
+func TestSolicitedNodeMulticast(t *testing.T) {
+	is := is.New(t)
+
+	addr, err := AddrSolicitedNodeMulticast(netip.MustParseAddr("2001:db8::1:800:200e:8c6c"))
+	is.NoErr(err)
+	t.Log(addr)
+	is.Equal(addr.String(), "ff02::1:ff0e:8c6c")
+
+	_, err = AddrSolicitedNodeMulticast(netip.MustParseAddr("ff02::1"))
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+}
+
+func TestArpa(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(Arpa(netip.MustParseAddr("2001:db8::1")), "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa")
+	// any IPV6 address has a reverse name
+	is.Equal(Arpa(netip.MustParseAddr("fe80::1")), "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.e.f.ip6.arpa")
+	is.Equal(Arpa(netip.MustParseAddr("192.0.2.1")), "")
+
+	name, err := PrefixArpa(netip.MustParsePrefix("2001:db8:abcd::/48"))
+	is.NoErr(err)
+	is.Equal(name, "d.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa")
+	name, err = PrefixArpa(netip.MustParsePrefix("::/0"))
+	is.NoErr(err)
+	is.Equal(name, "ip6.arpa")
+
+	_, err = PrefixArpa(netip.MustParsePrefix("2001:db8:abcd::/47"))
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+	_, err = PrefixArpa(netip.MustParsePrefix("192.0.2.0/24"))
+	is.True(errors.Is(err, util.ErrWrongFamily))
+}
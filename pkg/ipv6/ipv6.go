@@ -4,7 +4,6 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -17,26 +16,28 @@ import (
 
 // IPSummary summary of properties for an IP
 type IPSummary struct {
-	IPType                  string `yaml:"iptype,omitempty" json:"iptype,omitempty"`
-	TypePrefix              string `yaml:"typeprefix,omitempty" json:"typeprefix,omitempty"`
-	IP                      string `yaml:"ip,omitempty" json:"ip,omitempty"`
-	SolicitedNodeMulticast  string `yaml:"solicitednodemulticast,omitempty" json:"solicitednodemulticast,omitempty"`
-	Prefix                  string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
-	NetworkPrefix           string `yaml:"networkprefix,omitempty" json:"networkprefix,omitempty"`
-	RoutingPrefix           string `yaml:"routingprefix,omitempty" json:"routingprefix,omitempty"`
-	SubnetID                string `yaml:"subnetid,omitempty" json:"subnetid,omitempty"`
-	Subnets                 int64  `yaml:"subnets,omitempty" json:"subnets,omitempty"`
-	GlobalID                string `yaml:"globalid,omitempty" json:"globalid,omitempty"`
-	GroupID                 string `yaml:"groupid,omitempty" json:"groupid,omitempty"`
-	Groups                  int64  `yaml:"groups,omitempty" json:"groups,omitempty"`
-	InterfaceID             string `yaml:"interfaceid,omitempty" json:"interfaceid,omitempty"`
-	Addresses               int64  `yaml:"addresses,omitempty" json:"addresses,omitempty"`
-	DefaultGateway          string `yaml:"defaultgateway,omitempty" json:"defaultgateway,omitempty"`
-	Link                    string `yaml:"link,omitempty" json:"link,omitempty"`
-	IPV6Arpa                string `yaml:"ipv6arpa,omitempty" json:"ipv6arpa,omitempty"`
-	SubnetFirstAddress      string `yaml:"subnetfirstaddress,omitempty" json:"subnetfirstaddress,omitempty"`
-	SubnetLastAddress       string `yaml:"subnetlastaddress,omitempty" json:"subnetlastaddress,omitempty"`
-	FirstAddressFieldBinary string `yaml:"firstaddressbinary,omitempty" json:"firstaddressbinary,omitempty"`
+	IPType                  string               `yaml:"iptype,omitempty" json:"iptype,omitempty"`
+	TypePrefix              string               `yaml:"typeprefix,omitempty" json:"typeprefix,omitempty"`
+	AddressSpace            string               `yaml:"addressspace,omitempty" json:"addressspace,omitempty"`
+	SpecialPurpose          *util.SpecialPurpose `yaml:"specialpurpose,omitempty" json:"specialpurpose,omitempty"`
+	IP                      string               `yaml:"ip,omitempty" json:"ip,omitempty"`
+	SolicitedNodeMulticast  string               `yaml:"solicitednodemulticast,omitempty" json:"solicitednodemulticast,omitempty"`
+	Prefix                  string               `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	NetworkPrefix           string               `yaml:"networkprefix,omitempty" json:"networkprefix,omitempty"`
+	RoutingPrefix           string               `yaml:"routingprefix,omitempty" json:"routingprefix,omitempty"`
+	SubnetID                string               `yaml:"subnetid,omitempty" json:"subnetid,omitempty"`
+	Subnets                 int64                `yaml:"subnets,omitempty" json:"subnets,omitempty"`
+	GlobalID                string               `yaml:"globalid,omitempty" json:"globalid,omitempty"`
+	GroupID                 string               `yaml:"groupid,omitempty" json:"groupid,omitempty"`
+	Groups                  int64                `yaml:"groups,omitempty" json:"groups,omitempty"`
+	InterfaceID             string               `yaml:"interfaceid,omitempty" json:"interfaceid,omitempty"`
+	Addresses               int64                `yaml:"addresses,omitempty" json:"addresses,omitempty"`
+	DefaultGateway          string               `yaml:"defaultgateway,omitempty" json:"defaultgateway,omitempty"`
+	Link                    string               `yaml:"link,omitempty" json:"link,omitempty"`
+	IPV6Arpa                string               `yaml:"ipv6arpa,omitempty" json:"ipv6arpa,omitempty"`
+	SubnetFirstAddress      string               `yaml:"subnetfirstaddress,omitempty" json:"subnetfirstaddress,omitempty"`
+	SubnetLastAddress       string               `yaml:"subnetlastaddress,omitempty" json:"subnetlastaddress,omitempty"`
+	FirstAddressFieldBinary string               `yaml:"firstaddressbinary,omitempty" json:"firstaddressbinary,omitempty"`
 }
 
 // NewDomainInfoSet get new domain info list
@@ -100,7 +101,7 @@ const (
 const (
 	// GlobalUnicast IPV6 type
 	GlobalUnicast = iota
-	// UniqueLocal IPV6 type
+	// UniqueLocal IPV6 unique local type for fc00::/7, which was reported as Private before
 	UniqueLocal
 	// LinkLocalUnicast IPV6 type
 	LinkLocalUnicast
@@ -112,7 +113,7 @@ const (
 	InterfaceLocalMulticast
 	// LinkLocalMulticast IPV6 type
 	LinkLocalMulticast
-	// Private IPV6 type
+	// Private IPV4 private-use type, IPV6 unique local addresses are UniqueLocal
 	Private
 	// Unspecified IPV6 type
 	Unspecified
@@ -120,34 +121,24 @@ const (
 	Unknown
 )
 
-var typePrefixes = make(map[int]string)
-
-func init() {
-	typePrefixes[GlobalUnicast] = "2000::/3"
-	typePrefixes[UniqueLocal] = "fd00::/8"
-	typePrefixes[LinkLocalUnicast] = "fe80::/10"
-	typePrefixes[Loopback] = "::1/128"
-	typePrefixes[Multicast] = "ff00::/8"
-	typePrefixes[InterfaceLocalMulticast] = "FF00::/8"
-	typePrefixes[LinkLocalMulticast] = "FF00::/8"
-	typePrefixes[Private] = "fc00::/7"
-}
-
 // AddrTypePrefix the prefix for the IP type
+// This is the IANA address space block the address is in, or for loopback and
+// unspecified addresses which sit in the reserved ::/8 block the special-purpose
+// registry entry.
 func AddrTypePrefix(addr netip.Addr) (prefix netip.Prefix) {
-	addType := util.AddrType(addr)
-
-	p, ok := typePrefixes[addType]
-
-	switch ok {
-	case true:
-		var err error
-		prefix, err = netip.ParsePrefix(p)
-		if err != nil {
-			prefix = netip.Prefix{}
+	switch util.AddrType(addr) {
+	case Unknown:
+		return
+	case Loopback, Unspecified:
+		entry, ok := util.IP6SpecialPurpose(addr)
+		if ok {
+			prefix = entry.Prefix
 		}
 	default:
-		prefix = netip.Prefix{}
+		block, ok := util.IP6AddressSpace(addr)
+		if ok {
+			prefix = block.Prefix
+		}
 	}
 
 	return
@@ -172,16 +163,34 @@ func reverse[T any](s []T) {
 	}
 }
 
-// Use crypto/rand to generate a uint64 with value [0,max]
-// There will be no error if max is > 0
-func randUInt64(max int64) uint64 {
+// Use crypto/rand to generate a uint64 with value [0,max)
+func randUInt64(max int64) (inRange uint64, err error) {
 	bigInt, err := rand.Int(rand.Reader, big.NewInt(max))
 	if err != nil {
-		panic(err)
+		return
+	}
+	inRange = bigInt.Uint64()
+
+	return
+}
+
+// randBytes use crypto/rand to get count random bytes
+func randBytes(count int) (bytes []byte, err error) {
+	bytes = make([]byte, count)
+	_, err = rand.Read(bytes)
+
+	return
+}
+
+// randElement use crypto/rand to pick an element from a list
+func randElement(list []string) (element string, err error) {
+	i, err := randUInt64(int64(len(list)))
+	if err != nil {
+		return
 	}
-	inRange := bigInt.Uint64()
+	element = list[i]
 
-	return inRange
+	return
 }
 
 // RandAddrGlobalUnicast get a global unicast random IPV6 address
@@ -197,13 +206,21 @@ func RandAddrGlobalUnicast() (addr netip.Addr, err error) {
 		return
 	}
 
-	inRange := randUInt64(63-32) + 32
+	inRange, err := randUInt64(63 - 32)
+	if err != nil {
+		return
+	}
+	inRange += 32
+	subnet, err := randBytes(2)
+	if err != nil {
+		return
+	}
 
 	addrBytes := [16]byte{
 		byte(inRange), 0x01,
 		0xd, 0xb8,
 		0xca, 0xfe,
-		byte(randUInt64(256)), byte(randUInt64(256)),
+		subnet[0], subnet[1],
 		mac[0], mac[1],
 		mac[2], 0xff,
 		0xfe, mac[3],
@@ -261,12 +278,17 @@ func RandAddrPrivate() (addr netip.Addr, err error) {
 	first := byte(0xfc)
 	first |= 0x1
 
+	globalID, err := randBytes(7)
+	if err != nil {
+		return
+	}
+
 	// fc00::/7 is currently not defined
 	addrBytes := [16]byte{
-		first, byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)), // prepend with fd00::
+		first, globalID[0],
+		globalID[1], globalID[2],
+		globalID[3], globalID[4],
+		globalID[5], globalID[6], // prepend with fd00::
 		mac[0], mac[1],
 		mac[2], 0xff,
 		0xfe, mac[3],
@@ -279,79 +301,34 @@ func RandAddrPrivate() (addr netip.Addr, err error) {
 
 // RandAddrMulticast get a random multicast address
 func RandAddrMulticast() (addr netip.Addr, err error) {
-	// flag for 0 is reserved currently
-	flags := []string{"1", "2", "3"}
-	element := randUInt64(int64(len(flags))) + 1
-	flagStr := flags[element-1]
-
 	// scope 1 is interface-local and defined in interfaceLocalMulticast
 	// scope 2 is link-local multicast defined in randomLinkLocalMulticast
-	scopes := []string{"3", "4", "5", "8", "e", "f"}
-	element = randUInt64(int64(len(scopes))) + 1
-	scopeStr := scopes[element-1]
-
-	flagAndScope, err := strconv.ParseInt(fmt.Sprintf("%s%s", flagStr, scopeStr), 16, 64)
-
-	// multicast has prefix ff00::/8
-	addrBytes := [16]byte{
-		0xff, byte(flagAndScope),
-		0x0, 0x0,
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-	}
-	addr = netip.AddrFrom16(addrBytes)
-
-	return
+	return randAddrMulticast([]string{"3", "4", "5", "8", "e", "f"})
 }
 
 // RandAddrLinkLocalMulticast get a random link local multicast address
 func RandAddrLinkLocalMulticast() (addr netip.Addr, err error) {
-	// flag for 0 is reserved currently
-	flags := []string{"1", "2", "3"}
-	element := randUInt64(int64(len(flags))) + 1
-	flagStr := flags[element-1]
-
 	// a single scope applies to link local
-	scopes := []string{"2"}
-	element = randUInt64(int64(len(scopes))) + 1
-	scopeStr := scopes[element-1]
-
-	var flagAndScope int64
-	flagAndScope, err = strconv.ParseInt(fmt.Sprintf("%s%s", flagStr, scopeStr), 16, 64)
-	if err != nil {
-		return
-	}
-
-	addrBytes := [16]byte{
-		0xff, byte(flagAndScope),
-		0x0, 0x0,
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-	}
-	addr = netip.AddrFrom16(addrBytes)
-
-	return
+	return randAddrMulticast([]string{"2"})
 }
 
 // RandAddrInterfaceLocalMulticast get a random interface local multicast address
 func RandAddrInterfaceLocalMulticast() (addr netip.Addr, err error) {
-	// flag for 0 is reserved currently
-	flags := []string{"1", "2", "3"}
-	element := randUInt64(int64(len(flags))) + 1
-	flagStr := flags[element-1]
-
 	// a single scope applies to interface local
-	scopes := []string{"1"}
-	element = randUInt64(int64(len(scopes))) + 1
-	scopeStr := scopes[element-1]
+	return randAddrMulticast([]string{"1"})
+}
+
+// randAddrMulticast get a random multicast address with one of a list of scopes
+func randAddrMulticast(scopes []string) (addr netip.Addr, err error) {
+	// flag for 0 is reserved currently
+	flagStr, err := randElement([]string{"1", "2", "3"})
+	if err != nil {
+		return
+	}
+	scopeStr, err := randElement(scopes)
+	if err != nil {
+		return
+	}
 
 	// get hex value for flag plus scope
 	var flagAndScope int64
@@ -359,17 +336,17 @@ func RandAddrInterfaceLocalMulticast() (addr netip.Addr, err error) {
 	if err != nil {
 		return
 	}
+	group, err := randBytes(12)
+	if err != nil {
+		return
+	}
 
+	// multicast has prefix ff00::/8
 	addrBytes := [16]byte{
 		0xff, byte(flagAndScope),
 		0x0, 0x0,
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
-		byte(randUInt64(256)), byte(randUInt64(256)),
 	}
+	copy(addrBytes[4:], group)
 	addr = netip.AddrFrom16(addrBytes)
 
 	return
@@ -378,8 +355,8 @@ func RandAddrInterfaceLocalMulticast() (addr netip.Addr, err error) {
 // AddrSolicitedNodeMulticast get solicited node multicast address for incoming unicast address
 // EUI-64 compliance
 func AddrSolicitedNodeMulticast(addr netip.Addr) (newAddr netip.Addr, err error) {
-	if !(HasType(util.AddrType(addr), GlobalUnicast, LinkLocalUnicast, UniqueLocal, Private)) {
-		err = errors.New("not a unicast address")
+	if !(HasType(util.AddrType(addr), GlobalUnicast, LinkLocalUnicast, UniqueLocal)) {
+		err = fmt.Errorf("%w: %s is not a unicast address", util.ErrInvalidPrefix, addr)
 		return
 	}
 	// we need the last six characters from the address or 24 bits or 3 bytes
@@ -510,7 +487,7 @@ func hex2Bytes(hex string) (rangeBytes [8]byte, err error) {
 // bitRangeHex get hex value for a range of an IP's bits
 func bitRangeHex(addr netip.Addr, start, end int) (hex string, err error) {
 	if (end - start) > 64 {
-		err = errors.New("end-start > 64")
+		err = fmt.Errorf("%w: bit range %d-%d is more than 64 bits", util.ErrInvalidPrefix, start, end)
 		return
 	}
 	expectedLen := 10
@@ -623,16 +600,62 @@ func Addr2BitString(addr netip.Addr) (result string) {
 
 // Arpa get the IPV6 ARPA address
 func Arpa(addr netip.Addr) (addrStr string) {
-	if !HasType(util.AddrType(addr), GlobalUnicast) {
+	addrStr, _ = PrefixArpa(netip.PrefixFrom(addr, 128))
+
+	return
+}
+
+// PrefixArpa get the ip6.arpa zone name for a prefix on a nibble boundary
+func PrefixArpa(prefix netip.Prefix) (name string, err error) {
+	if !prefix.Addr().Is6() {
+		err = fmt.Errorf("%w: %s is not an IPV6 prefix", util.ErrWrongFamily, prefix)
 		return
 	}
+	if prefix.Bits()%4 != 0 {
+		err = fmt.Errorf("%w: %s is not on a nibble boundary", util.ErrInvalidPrefix, prefix)
+		return
+	}
+	nibbles := strings.Split(strings.ReplaceAll(prefix.Masked().Addr().StringExpanded(), ":", ""), "")
+	nibbles = nibbles[:prefix.Bits()/4]
+	reverse(nibbles)
+	nibbles = append(nibbles, "ip6.arpa")
 
-	addrStr = addr.StringExpanded()
-	addrStr = strings.ReplaceAll(addrStr, ":", "")
-	addrSlice := strings.Split(addrStr, "")
-	reverse(addrSlice)
+	name = strings.Join(nibbles, ".")
 
-	addrStr = fmt.Sprintf("%s.ip6.arpa", strings.Join(addrSlice, "."))
+	return
+}
+
+// FromArpa get the address or prefix for a full or partial ip6.arpa name
+// Each nibble is four bits so a name with 12 nibbles is a /48.
+func FromArpa(name string) (prefix netip.Prefix, err error) {
+	fqdn := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if fqdn != "ip6.arpa" && !strings.HasSuffix(fqdn, ".ip6.arpa") {
+		err = fmt.Errorf("%w: %q is not an ip6.arpa name", util.ErrInvalidPrefix, name)
+		return
+	}
+	nibbles := strings.Split(strings.TrimSuffix(fqdn, "ip6.arpa"), ".")
+	nibbles = nibbles[:len(nibbles)-1]
+	if len(nibbles) > 32 {
+		err = fmt.Errorf("%w: %q has more than 32 nibbles", util.ErrInvalidPrefix, name)
+		return
+	}
+	reverse(nibbles)
+
+	var bytes [16]byte
+	for i, nibble := range nibbles {
+		var value uint64
+		value, err = strconv.ParseUint(nibble, 16, 4)
+		if err != nil || len(nibble) != 1 {
+			err = fmt.Errorf("%w: %q has an invalid nibble %q", util.ErrInvalidPrefix, name, nibble)
+			return
+		}
+		if i%2 == 0 {
+			bytes[i/2] = byte(value) << 4
+		} else {
+			bytes[i/2] |= byte(value)
+		}
+	}
+	prefix = netip.PrefixFrom(netip.AddrFrom16(bytes), len(nibbles)*4)
 
 	return
 }
@@ -790,8 +813,8 @@ func randomMacBytesForInterface(local, unicast bool) (bytes [6]byte, err error)
 }
 
 // addrRandSubnetID get a random subnet for IPV6
-func addrRandSubnetID() uint16 {
-	rand := randUInt64(65_536)
+func addrRandSubnetID() (uint16, error) {
+	rand, err := randUInt64(65_536)
 
-	return uint16(rand)
+	return uint16(rand), err
 }
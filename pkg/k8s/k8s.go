@@ -0,0 +1,276 @@
+package k8s
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+const (
+	// DefaultNodeMaskIPv4 the kube-controller-manager default node CIDR mask size for IPV4
+	DefaultNodeMaskIPv4 = 24
+	// DefaultNodeMaskIPv6 the kube-controller-manager default node CIDR mask size for IPV6
+	DefaultNodeMaskIPv6 = 64
+	// DefaultMaxPods the kubelet default for the most pods on a node
+	DefaultMaxPods = 110
+	// MaxNodeMaskDiff the most bits kube-controller-manager allows between a cluster CIDR and its node CIDRs
+	MaxNodeMaskDiff = 16
+	// MaxServiceHostBits the most host bits kube-apiserver allows in a service CIDR, /12 for IPV4 and /108 for IPV6
+	MaxServiceHostBits = 20
+	// dnsOffset the offset in the service CIDR kubeadm gives the cluster DNS service
+	dnsOffset = 10
+)
+
+const (
+	// ClusterKind a cluster CIDR pod addresses come from
+	ClusterKind = "cluster"
+	// ServiceKind a service CIDR cluster IPs come from
+	ServiceKind = "service"
+	// NodeKind a network the nodes are on
+	NodeKind = "node"
+	// VPCKind a VPC or other network the cluster is in
+	VPCKind = "VPC"
+)
+
+// Config the CIDRs and sizes of a cluster
+// A dual-stack cluster has an IPV4 and an IPV6 cluster CIDR and may also have a service
+// CIDR for each family. Mask sizes and max pods of 0 get the Kubernetes defaults.
+type Config struct {
+	ClusterCIDRs []netip.Prefix
+	ServiceCIDRs []netip.Prefix
+	NodeMaskIPv4 int
+	NodeMaskIPv6 int
+	Nodes        int
+	MaxPods      int
+	NodeNetworks []netip.Prefix
+	VPCNetworks  []netip.Prefix
+}
+
+// PodNetwork a cluster CIDR split into node CIDRs
+type PodNetwork struct {
+	CIDR     netip.Prefix
+	NodeMask int
+	// MaxNodes the number of node CIDRs in the cluster CIDR
+	MaxNodes int
+	// NodeAddrs the number of addresses in a node CIDR
+	NodeAddrs *big.Int
+	// PodsPerNode the most pods a node can run, limited by max pods and node CIDR size
+	PodsPerNode int
+}
+
+// ServiceNetwork a service CIDR
+type ServiceNetwork struct {
+	CIDR netip.Prefix
+	// IPs the number of cluster IPs that can be allocated
+	IPs *big.Int
+	// APIServerIP the cluster IP of the kubernetes service
+	APIServerIP netip.Addr
+	// DNSIP the cluster IP kubeadm gives the cluster DNS service
+	DNSIP netip.Addr
+}
+
+// Plan the capacity of a cluster and the problems found with its CIDRs
+type Plan struct {
+	Config
+	Pods     []PodNetwork
+	Services []ServiceNetwork
+	// MaxNodes the most nodes every cluster CIDR has room for
+	MaxNodes int
+	// MaxPods the most pods the cluster can run at MaxNodes
+	MaxPods  int64
+	Overlaps []ipv4subnet.Overlap
+	Warnings []string
+}
+
+// NewPlan work out the capacity of a cluster and check its CIDRs
+// Limits kube-controller-manager enforces are errors while a service CIDR larger than
+// kube-apiserver allows, more nodes than there are node CIDRs and node CIDRs too small
+// for max pods are warnings. Overlaps between cluster, service, node and VPC networks
+// are listed except for node networks inside VPC networks, which is expected.
+func NewPlan(config Config) (plan *Plan, err error) {
+	if config.MaxPods == 0 {
+		config.MaxPods = DefaultMaxPods
+	}
+	if config.NodeMaskIPv4 == 0 {
+		config.NodeMaskIPv4 = DefaultNodeMaskIPv4
+	}
+	if config.NodeMaskIPv6 == 0 {
+		config.NodeMaskIPv6 = DefaultNodeMaskIPv6
+	}
+	if len(config.ClusterCIDRs) == 0 {
+		err = fmt.Errorf("%w: no cluster CIDR", util.ErrInvalidPrefix)
+		return
+	}
+	config.ClusterCIDRs, err = checkStack(ClusterKind, config.ClusterCIDRs)
+	if err != nil {
+		return
+	}
+	config.ServiceCIDRs, err = checkStack(ServiceKind, config.ServiceCIDRs)
+	if err != nil {
+		return
+	}
+	config.NodeNetworks = unmapAll(config.NodeNetworks)
+	config.VPCNetworks = unmapAll(config.VPCNetworks)
+
+	plan = &Plan{Config: config}
+	for _, cidr := range config.ClusterCIDRs {
+		var pods PodNetwork
+		pods, err = newPodNetwork(cidr, config)
+		if err != nil {
+			return
+		}
+		if plan.MaxNodes == 0 || pods.MaxNodes < plan.MaxNodes {
+			plan.MaxNodes = pods.MaxNodes
+		}
+		if config.Nodes > pods.MaxNodes {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d nodes need more than the %d /%d node CIDRs in %s",
+				config.Nodes, pods.MaxNodes, pods.NodeMask, cidr))
+		}
+		if pods.PodsPerNode < config.MaxPods {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("/%d node CIDRs in %s have room for %d pods, fewer than max pods of %d",
+				pods.NodeMask, cidr, pods.PodsPerNode, config.MaxPods))
+		}
+		plan.Pods = append(plan.Pods, pods)
+	}
+	podsPerNode := plan.Pods[0].PodsPerNode
+	for _, pods := range plan.Pods[1:] {
+		podsPerNode = min(podsPerNode, pods.PodsPerNode)
+	}
+	plan.MaxPods = int64(plan.MaxNodes) * int64(podsPerNode)
+
+	for _, cidr := range config.ServiceCIDRs {
+		hostBits := cidr.Addr().BitLen() - cidr.Bits()
+		if hostBits > MaxServiceHostBits {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("service CIDR %s is larger than the /%d kube-apiserver allows",
+				cidr, cidr.Addr().BitLen()-MaxServiceHostBits))
+		}
+		plan.Services = append(plan.Services, newServiceNetwork(cidr))
+	}
+
+	plan.Overlaps = overlaps(config)
+
+	return
+}
+
+// newPodNetwork split a cluster CIDR into node CIDRs
+func newPodNetwork(cidr netip.Prefix, config Config) (pods PodNetwork, err error) {
+	pods = PodNetwork{CIDR: cidr, NodeMask: config.NodeMaskIPv4}
+	if cidr.Addr().Is6() {
+		pods.NodeMask = config.NodeMaskIPv6
+	}
+	if pods.NodeMask < cidr.Bits() || pods.NodeMask > cidr.Addr().BitLen() {
+		err = fmt.Errorf("%w: node CIDR mask size %d must be from %d to %d for cluster CIDR %s",
+			util.ErrInvalidPrefix, pods.NodeMask, cidr.Bits(), cidr.Addr().BitLen(), cidr)
+		return
+	}
+	if pods.NodeMask-cidr.Bits() > MaxNodeMaskDiff {
+		err = fmt.Errorf("%w: node CIDR mask size %d is more than %d bits longer than cluster CIDR %s",
+			util.ErrInvalidPrefix, pods.NodeMask, MaxNodeMaskDiff, cidr)
+		return
+	}
+	pods.MaxNodes = 1 << (pods.NodeMask - cidr.Bits())
+	pods.NodeAddrs = blockSize(cidr.Addr().BitLen() - pods.NodeMask)
+
+	// the network and broadcast addresses of an IPV4 node CIDR are not given to pods
+	podAddrs := new(big.Int).Set(pods.NodeAddrs)
+	if cidr.Addr().Is4() {
+		podAddrs.Sub(podAddrs, big.NewInt(2))
+	}
+	pods.PodsPerNode = config.MaxPods
+	if podAddrs.Cmp(big.NewInt(int64(config.MaxPods))) < 0 {
+		pods.PodsPerNode = int(max(podAddrs.Int64(), 0))
+	}
+
+	return
+}
+
+// newServiceNetwork get the cluster IPs of a service CIDR
+// kube-apiserver does not allocate the network address or, for IPV4, the broadcast address.
+func newServiceNetwork(cidr netip.Prefix) (service ServiceNetwork) {
+	service = ServiceNetwork{CIDR: cidr}
+	service.IPs = blockSize(cidr.Addr().BitLen() - cidr.Bits())
+	service.IPs.Sub(service.IPs, big.NewInt(1))
+	if cidr.Addr().Is4() {
+		service.IPs.Sub(service.IPs, big.NewInt(1))
+	}
+	if service.IPs.Sign() < 0 {
+		service.IPs.SetInt64(0)
+	}
+
+	r := ipv4subnet.NewRangeFromPrefix(cidr)
+	addr := cidr.Addr()
+	for i := 1; i <= dnsOffset; i++ {
+		addr = addr.Next()
+		if !addr.IsValid() || r.Last().Less(addr) {
+			break
+		}
+		if i == 1 {
+			service.APIServerIP = addr
+		}
+		if i == dnsOffset {
+			service.DNSIP = addr
+		}
+	}
+
+	return
+}
+
+// checkStack check that there is at most one CIDR of each family
+func checkStack(kind string, cidrs []netip.Prefix) (checked []netip.Prefix, err error) {
+	checked = unmapAll(cidrs)
+	if len(checked) > 2 {
+		err = fmt.Errorf("%w: %d %s CIDRs given, a dual-stack cluster has one IPV4 and one IPV6 CIDR",
+			util.ErrInvalidPrefix, len(checked), kind)
+		return
+	}
+	if len(checked) == 2 && checked[0].Addr().Is4() == checked[1].Addr().Is4() {
+		err = fmt.Errorf("%w: %s CIDRs %s and %s are the same family, a dual-stack cluster has one IPV4 and one IPV6 CIDR",
+			util.ErrWrongFamily, kind, checked[0], checked[1])
+	}
+
+	return
+}
+
+// overlaps find the overlaps between the networks of a cluster
+func overlaps(config Config) (found []ipv4subnet.Overlap) {
+	prefixes := []ipv4subnet.NamedPrefix{}
+	add := func(kind string, cidrs []netip.Prefix) {
+		for _, cidr := range cidrs {
+			prefixes = append(prefixes, ipv4subnet.NamedPrefix{Name: kind, Prefix: cidr})
+		}
+	}
+	add(ClusterKind, config.ClusterCIDRs)
+	add(ServiceKind, config.ServiceCIDRs)
+	add(NodeKind, config.NodeNetworks)
+	add(VPCKind, config.VPCNetworks)
+
+	infrastructure := func(kind string) bool {
+		return kind == NodeKind || kind == VPCKind
+	}
+	for _, overlap := range ipv4subnet.Overlaps(prefixes) {
+		if infrastructure(overlap.First.Name) && infrastructure(overlap.Second.Name) {
+			continue
+		}
+		found = append(found, overlap)
+	}
+
+	return
+}
+
+// unmapAll get masked prefixes with IPV4-mapped IPV6 prefixes as IPV4
+func unmapAll(prefixes []netip.Prefix) (unmapped []netip.Prefix) {
+	for _, prefix := range prefixes {
+		prefix = util.UnmapPrefix(prefix)
+		unmapped = append(unmapped, prefix.Masked())
+	}
+
+	return
+}
+
+// blockSize get the number of addresses for a number of host bits
+func blockSize(hostBits int) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+}
@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/imarsman/iptools/pkg/util"
+	"github.com/matryer/is"
+)
+
+func prefixes(values ...string) (list []netip.Prefix) {
+	for _, value := range values {
+		list = append(list, netip.MustParsePrefix(value))
+	}
+
+	return
+}
+
+func TestPlan(t *testing.T) {
+	is := is.New(t)
+
+	plan, err := NewPlan(Config{
+		ClusterCIDRs: prefixes("10.244.0.0/16"),
+		ServiceCIDRs: prefixes("10.96.0.0/12"),
+		Nodes:        100,
+	})
+	is.NoErr(err)
+	is.Equal(plan.MaxNodes, 256)
+	is.Equal(plan.Pods[0].PodsPerNode, 110)
+	is.Equal(plan.Pods[0].NodeAddrs.Int64(), int64(256))
+	is.Equal(plan.MaxPods, int64(256*110))
+	is.Equal(plan.Services[0].IPs.Int64(), int64(1<<20-2))
+	is.Equal(plan.Services[0].APIServerIP, netip.MustParseAddr("10.96.0.1"))
+	is.Equal(plan.Services[0].DNSIP, netip.MustParseAddr("10.96.0.10"))
+	is.Equal(len(plan.Warnings), 0)
+	is.Equal(len(plan.Overlaps), 0)
+
+	// small node CIDRs, too many nodes and a large IPV6 service CIDR
+	plan, err = NewPlan(Config{
+		ClusterCIDRs: prefixes("10.244.0.0/20", "fd00:10:244::/56"),
+		ServiceCIDRs: prefixes("10.96.0.0/16", "fd00:10:96::/64"),
+		NodeMaskIPv4: 26,
+		Nodes:        100,
+	})
+	is.NoErr(err)
+	for _, warning := range plan.Warnings {
+		t.Log(warning)
+	}
+	is.Equal(plan.MaxNodes, 64)
+	is.Equal(plan.Pods[0].PodsPerNode, 62)
+	is.Equal(plan.Pods[1].MaxNodes, 256)
+	is.Equal(plan.Pods[1].PodsPerNode, 110)
+	is.Equal(plan.MaxPods, int64(64*62))
+	is.Equal(plan.Services[1].DNSIP, netip.MustParseAddr("fd00:10:96::a"))
+	is.Equal(len(plan.Warnings), 3)
+
+	// overlaps with everything but node networks inside the VPC
+	plan, err = NewPlan(Config{
+		ClusterCIDRs: prefixes("10.0.0.0/16"),
+		ServiceCIDRs: prefixes("10.0.128.0/20"),
+		NodeNetworks: prefixes("10.1.0.0/24"),
+		VPCNetworks:  prefixes("10.0.0.0/8"),
+	})
+	is.NoErr(err)
+	for _, overlap := range plan.Overlaps {
+		t.Log(overlap.First, overlap.Second)
+	}
+	is.Equal(len(plan.Overlaps), 3)
+
+	_, err = NewPlan(Config{ClusterCIDRs: prefixes("10.0.0.0/8"), NodeMaskIPv4: 8 + MaxNodeMaskDiff + 1})
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+
+	_, err = NewPlan(Config{ClusterCIDRs: prefixes("10.244.0.0/16"), NodeMaskIPv4: 8})
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrInvalidPrefix))
+
+	_, err = NewPlan(Config{ClusterCIDRs: prefixes("10.244.0.0/16", "10.245.0.0/16")})
+	t.Log(err)
+	is.True(errors.Is(err, util.ErrWrongFamily))
+}
@@ -0,0 +1,203 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/imarsman/iptools/pkg/ipv4subnet"
+	"github.com/imarsman/iptools/pkg/util"
+)
+
+// Plan an addressing plan of nested IPV4 and IPV6 networks
+type Plan struct {
+	Name     string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Networks []*Network `json:"networks" yaml:"networks"`
+}
+
+// Network a network in a plan such as a region, site or VLAN and the networks inside it
+type Network struct {
+	Name     string     `json:"name" yaml:"name"`
+	Kind     string     `json:"kind,omitempty" yaml:"kind,omitempty"`
+	VLAN     int        `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+	Prefix   string     `json:"prefix" yaml:"prefix"`
+	Purpose  string     `json:"purpose,omitempty" yaml:"purpose,omitempty"`
+	Networks []*Network `json:"networks,omitempty" yaml:"networks,omitempty"`
+}
+
+// Issue a problem found in a plan
+type Issue struct {
+	Path    string
+	Message string
+}
+
+// String get the issue with the path of the network it is for
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// Details the describe fields for a network
+// Broadcast is empty for IPV6 which has no broadcast address.
+type Details struct {
+	Network     string `json:"network" yaml:"network"`
+	Broadcast   string `json:"broadcast,omitempty" yaml:"broadcast,omitempty"`
+	Mask        string `json:"mask" yaml:"mask"`
+	UsableHosts string `json:"usable_hosts" yaml:"usable_hosts"`
+}
+
+// Read read a plan in YAML or JSON
+// Unknown keys are rejected so misspelt keys are not silently ignored.
+func Read(reader io.Reader) (plan *Plan, err error) {
+	plan = new(Plan)
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	err = decoder.Decode(plan)
+	if err == io.EOF {
+		err = fmt.Errorf("plan is empty")
+	}
+
+	return
+}
+
+// Walk call fn for every network in the plan in order along with its path and depth
+// The path is made of the names of the network and the networks it is inside.
+func (p *Plan) Walk(fn func(network *Network, path string, depth int)) {
+	for _, network := range p.Networks {
+		network.walk(fn, "", 0)
+	}
+}
+
+// walk call fn for the network and the networks inside it
+func (n *Network) walk(fn func(network *Network, path string, depth int), parentPath string, depth int) {
+	path := n.Name
+	if path == "" {
+		path = n.Prefix
+	}
+	if parentPath != "" {
+		path = parentPath + "/" + path
+	}
+	fn(n, path, depth)
+	for _, child := range n.Networks {
+		child.walk(fn, path, depth+1)
+	}
+}
+
+// ParsePrefix parse the prefix for a network treating IPV4 mapped IPV6 prefixes as IPV4
+func (n *Network) ParsePrefix() (prefix netip.Prefix, err error) {
+	prefix, err = netip.ParsePrefix(strings.TrimSpace(n.Prefix))
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ipv4subnet.ErrInvalidPrefix, err)
+		return
+	}
+	prefix = util.UnmapPrefix(prefix)
+
+	return
+}
+
+// Details get the network, broadcast, mask and usable hosts for a network
+func (n *Network) Details() (details Details, err error) {
+	prefix, err := n.ParsePrefix()
+	if err != nil {
+		return
+	}
+	prefix = prefix.Masked()
+
+	if prefix.Addr().Is4() {
+		var s *ipv4subnet.Subnet
+		s, err = ipv4subnet.NewNamedFromPrefix(prefix.String(), n.Name)
+		if err != nil {
+			return
+		}
+		details = Details{
+			Network:     s.NetworkAddr().String(),
+			Broadcast:   s.BroadcastAddr().String(),
+			Mask:        s.SubnetMask().String(),
+			UsableHosts: fmt.Sprintf("%d", s.UsableHosts()),
+		}
+		return
+	}
+
+	allOnes := netip.AddrFrom16([16]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	})
+	hosts := new(big.Int).Lsh(big.NewInt(1), uint(128-prefix.Bits()))
+	details = Details{
+		Network:     prefix.Addr().String(),
+		Mask:        netip.PrefixFrom(allOnes, prefix.Bits()).Masked().Addr().String(),
+		UsableHosts: hosts.String(),
+	}
+
+	return
+}
+
+// Validate check that prefixes parse and are aligned, that every network is inside
+// the network containing it and that networks at the same level neither overlap
+// nor share a name
+func (p *Plan) Validate() (issues []Issue) {
+	return validate(p.Networks, "", netip.Prefix{})
+}
+
+// validate check a list of sibling networks and the networks inside them
+func validate(networks []*Network, parentPath string, parent netip.Prefix) (issues []Issue) {
+	type sibling struct {
+		path   string
+		prefix netip.Prefix
+	}
+	siblings := []sibling{}
+	names := make(map[string]string)
+
+	for _, network := range networks {
+		path := network.Name
+		if path == "" {
+			path = network.Prefix
+		}
+		if parentPath != "" {
+			path = parentPath + "/" + path
+		}
+		add := func(format string, a ...any) {
+			issues = append(issues, Issue{Path: path, Message: fmt.Sprintf(format, a...)})
+		}
+
+		if network.Name == "" {
+			add("no name")
+		} else if other, ok := names[network.Name]; ok {
+			add("name is also used by %s", other)
+		} else {
+			names[network.Name] = path
+		}
+
+		prefix, err := network.ParsePrefix()
+		if err != nil {
+			add("%v", err)
+			// networks inside can't be checked against an invalid prefix
+			issues = append(issues, validate(network.Networks, path, netip.Prefix{})...)
+			continue
+		}
+		if prefix != prefix.Masked() {
+			add("%s is not aligned, the network is %s", prefix, prefix.Masked())
+			prefix = prefix.Masked()
+		}
+		if parent.IsValid() {
+			if prefix.Addr().BitLen() != parent.Addr().BitLen() {
+				add("%s is not in the same family as %s", prefix, parent)
+			} else if prefix.Bits() < parent.Bits() || !parent.Contains(prefix.Addr()) {
+				add("%s is not inside %s", prefix, parent)
+			}
+		}
+		for _, s := range siblings {
+			if s.prefix.Overlaps(prefix) {
+				add("%s overlaps %s %s", prefix, s.path, s.prefix)
+			}
+		}
+		siblings = append(siblings, sibling{path: path, prefix: prefix})
+
+		issues = append(issues, validate(network.Networks, path, prefix)...)
+	}
+
+	return
+}
@@ -0,0 +1,94 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const goodPlan = `
+name: corp
+networks:
+  - name: us-east
+    kind: region
+    prefix: 10.0.0.0/12
+    networks:
+      - name: nyc
+        kind: site
+        prefix: 10.0.0.0/16
+        networks:
+          - name: users
+            kind: vlan
+            vlan: 10
+            prefix: 10.0.1.0/24
+            purpose: workstations
+          - name: p2p
+            kind: vlan
+            prefix: 10.0.2.0/31
+  - name: v6
+    prefix: 2001:db8::/32
+    networks:
+      - name: nyc
+        prefix: 2001:db8:1::/48
+`
+
+func TestValidate(t *testing.T) {
+	is := is.New(t)
+
+	plan, err := Read(strings.NewReader(goodPlan))
+	is.NoErr(err)
+	is.Equal(len(plan.Validate()), 0)
+
+	paths := []string{}
+	plan.Walk(func(network *Network, path string, depth int) {
+		paths = append(paths, path)
+	})
+	is.Equal(paths, []string{"us-east", "us-east/nyc", "us-east/nyc/users", "us-east/nyc/p2p", "v6", "v6/nyc"})
+
+	badPlan := `
+networks:
+  - name: region
+    prefix: 10.0.0.0/16
+    networks:
+      - name: a
+        prefix: 10.0.1.5/24
+      - name: b
+        prefix: 10.0.1.128/25
+      - name: a
+        prefix: 10.1.0.0/24
+      - name: c
+        prefix: 2001:db8::/64
+      - name: d
+        prefix: 10.0.300.0/24
+`
+	plan, err = Read(strings.NewReader(badPlan))
+	is.NoErr(err)
+	issues := []string{}
+	for _, issue := range plan.Validate() {
+		issues = append(issues, issue.String())
+	}
+	t.Log(strings.Join(issues, "\n"))
+	is.Equal(len(issues), 6)
+	is.Equal(issues[0], "region/a: 10.0.1.5/24 is not aligned, the network is 10.0.1.0/24")
+	is.Equal(issues[1], "region/b: 10.0.1.128/25 overlaps region/a 10.0.1.0/24")
+	is.Equal(issues[2], "region/a: name is also used by region/a")
+	is.Equal(issues[3], "region/a: 10.1.0.0/24 is not inside 10.0.0.0/16")
+	is.Equal(issues[4], "region/c: 2001:db8::/64 is not in the same family as 10.0.0.0/16")
+	is.True(strings.HasPrefix(issues[5], "region/d: invalid prefix"))
+
+	_, err = Read(strings.NewReader("networks:\n  - name: a\n    prefx: 10.0.0.0/8\n"))
+	is.True(err != nil)
+}
+
+func TestDetails(t *testing.T) {
+	is := is.New(t)
+
+	details, err := (&Network{Prefix: "10.0.1.0/24"}).Details()
+	is.NoErr(err)
+	is.Equal(details, Details{Network: "10.0.1.0", Broadcast: "10.0.1.255", Mask: "255.255.255.0", UsableHosts: "254"})
+
+	details, err = (&Network{Prefix: "2001:db8:1::/48"}).Details()
+	is.NoErr(err)
+	is.Equal(details, Details{Network: "2001:db8:1::", Mask: "ffff:ffff:ffff::", UsableHosts: "1208925819614629174706176"})
+}
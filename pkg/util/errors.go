@@ -0,0 +1,16 @@
+package util
+
+import "errors"
+
+// Errors shared by the ipv4subnet and ipv6 packages. Errors returned by those
+// packages wrap these so callers can match them with errors.Is.
+var (
+	// ErrInvalidPrefix a prefix or address could not be parsed or is out of range
+	ErrInvalidPrefix = errors.New("invalid prefix")
+	// ErrSplitToFewerBits a subnet was split into subnets with fewer prefix bits
+	ErrSplitToFewerBits = errors.New("cannot split to fewer bits than the parent")
+	// ErrAddressOverflow a calculation went past the end of the address space
+	ErrAddressOverflow = errors.New("address overflow")
+	// ErrWrongFamily an IPV6 address was given where IPV4 was expected or vice versa
+	ErrWrongFamily = errors.New("wrong address family")
+)
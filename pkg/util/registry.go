@@ -0,0 +1,232 @@
+package util
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ip4SpecialRegistryCSV a copy of the IANA IPv4 Special-Purpose Address Registry
+// https://www.iana.org/assignments/iana-ipv4-special-registry/iana-ipv4-special-registry.xhtml
+//
+//go:embed iana-ipv4-special-registry.csv
+var ip4SpecialRegistryCSV string
+
+// ip6SpecialRegistryCSV a copy of the IANA IPv6 Special-Purpose Address Registry
+// https://www.iana.org/assignments/iana-ipv6-special-registry/iana-ipv6-special-registry.xhtml
+//
+//go:embed iana-ipv6-special-registry.csv
+var ip6SpecialRegistryCSV string
+
+// ip6AddressSpaceCSV a copy of the IANA Internet Protocol Version 6 Address Space registry
+// which sets aside 2000::/3 for global unicast
+// https://www.iana.org/assignments/ipv6-address-space/ipv6-address-space.xhtml
+//
+//go:embed iana-ipv6-address-space.csv
+var ip6AddressSpaceCSV string
+
+// SpecialPurpose an entry in an IANA special-purpose address registry (RFC 6890)
+// Source and Destination say whether an address in the block can be used as a
+// source or destination address, Forwardable whether routers may forward packets
+// with it, GloballyReachable whether it is reachable beyond its administrative
+// domain and ReservedByProtocol whether it is reserved by a protocol standard.
+type SpecialPurpose struct {
+	Prefix             netip.Prefix `json:"prefix" yaml:"prefix"`
+	Name               string       `json:"name" yaml:"name"`
+	RFC                string       `json:"rfc" yaml:"rfc"`
+	Allocated          string       `json:"allocated" yaml:"allocated"`
+	Terminated         string       `json:"terminated,omitempty" yaml:"terminated,omitempty"`
+	Source             bool         `json:"source" yaml:"source"`
+	Destination        bool         `json:"destination" yaml:"destination"`
+	Forwardable        bool         `json:"forwardable" yaml:"forwardable"`
+	GloballyReachable  bool         `json:"globally_reachable" yaml:"globally_reachable"`
+	ReservedByProtocol bool         `json:"reserved_by_protocol" yaml:"reserved_by_protocol"`
+}
+
+// AddressBlock an entry in the IANA IPV6 address space registry
+type AddressBlock struct {
+	Prefix netip.Prefix `json:"prefix" yaml:"prefix"`
+	Name   string       `json:"name" yaml:"name"`
+	RFC    string       `json:"rfc" yaml:"rfc"`
+}
+
+// The registries are embedded so they are parsed when the package loads, as with
+// regexp.MustCompile. A registry that does not parse is a build problem and
+// TestRegistriesParse fails for it.
+var (
+	ip4SpecialRegistry = mustParse(parseSpecialRegistry(ip4SpecialRegistryCSV))
+	ip6SpecialRegistry = mustParse(parseSpecialRegistry(ip6SpecialRegistryCSV))
+	ip6AddressSpace    = mustParse(parseAddressSpace(ip6AddressSpaceCSV))
+)
+
+// mustParse get the entries of an embedded registry, panicking if it did not parse
+func mustParse[T any](entries []T, err error) []T {
+	if err != nil {
+		panic(err)
+	}
+
+	return entries
+}
+
+// IP4SpecialPurposeRegistry get the entries in the IPV4 special-purpose address registry
+func IP4SpecialPurposeRegistry() []SpecialPurpose {
+	entries := make([]SpecialPurpose, len(ip4SpecialRegistry))
+	copy(entries, ip4SpecialRegistry)
+
+	return entries
+}
+
+// IP4SpecialPurpose get the most specific special-purpose registry entry for an IPV4 address
+func IP4SpecialPurpose(addr netip.Addr) (entry SpecialPurpose, ok bool) {
+	addr = addr.Unmap()
+	if !addr.Is4() {
+		return
+	}
+
+	return IP4SpecialPurposePrefix(netip.PrefixFrom(addr, 32))
+}
+
+// IP4SpecialPurposePrefix get the most specific special-purpose registry entry containing all of an IPV4 prefix
+func IP4SpecialPurposePrefix(prefix netip.Prefix) (entry SpecialPurpose, ok bool) {
+	return mostSpecific(IP4SpecialPurposeRegistry(), prefix)
+}
+
+// IP6SpecialPurposeRegistry get the entries in the IPV6 special-purpose address registry
+func IP6SpecialPurposeRegistry() []SpecialPurpose {
+	entries := make([]SpecialPurpose, len(ip6SpecialRegistry))
+	copy(entries, ip6SpecialRegistry)
+
+	return entries
+}
+
+// IP6SpecialPurpose get the most specific special-purpose registry entry for an IPV6 address
+// IPV4 mapped addresses match the IPV4-mapped entry rather than the IPV4 registry.
+func IP6SpecialPurpose(addr netip.Addr) (entry SpecialPurpose, ok bool) {
+	if !addr.Is6() {
+		return
+	}
+
+	return IP6SpecialPurposePrefix(netip.PrefixFrom(addr, 128))
+}
+
+// IP6SpecialPurposePrefix get the most specific special-purpose registry entry containing all of an IPV6 prefix
+func IP6SpecialPurposePrefix(prefix netip.Prefix) (entry SpecialPurpose, ok bool) {
+	return mostSpecific(IP6SpecialPurposeRegistry(), prefix)
+}
+
+// AddrSpecialPurpose get the most specific special-purpose registry entry for an IPV4 or IPV6 address
+func AddrSpecialPurpose(addr netip.Addr) (entry SpecialPurpose, ok bool) {
+	if addr.Is4() {
+		return IP4SpecialPurpose(addr)
+	}
+
+	return IP6SpecialPurpose(addr)
+}
+
+// IP6AddressSpaceRegistry get the blocks in the IPV6 address space registry
+func IP6AddressSpaceRegistry() []AddressBlock {
+	blocks := make([]AddressBlock, len(ip6AddressSpace))
+	copy(blocks, ip6AddressSpace)
+
+	return blocks
+}
+
+// IP6AddressSpace get the address space registry block an IPV6 address is in
+// The blocks cover all of the address space without overlapping.
+func IP6AddressSpace(addr netip.Addr) (block AddressBlock, ok bool) {
+	if !addr.Is6() {
+		return
+	}
+	for _, b := range IP6AddressSpaceRegistry() {
+		if b.Prefix.Contains(addr) {
+			return b, true
+		}
+	}
+
+	return
+}
+
+// mostSpecific get the entry with the longest prefix containing all of prefix
+func mostSpecific(entries []SpecialPurpose, prefix netip.Prefix) (entry SpecialPurpose, ok bool) {
+	prefix = prefix.Masked()
+	for _, e := range entries {
+		if e.Prefix.Bits() > prefix.Bits() || !e.Prefix.Contains(prefix.Addr()) {
+			continue
+		}
+		if !ok || e.Prefix.Bits() > entry.Prefix.Bits() {
+			entry, ok = e, true
+		}
+	}
+
+	return
+}
+
+// parseSpecialRegistry parse a registry in the CSV layout published by IANA
+// Footnote markers such as "True [1]" are ignored and N/A is taken as false.
+func parseSpecialRegistry(data string) (entries []SpecialPurpose, err error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return
+	}
+	flag := func(value string) bool {
+		return strings.HasPrefix(strings.TrimSpace(value), "True")
+	}
+	for i, record := range records[1:] {
+		if len(record) != 10 {
+			err = fmt.Errorf("registry line %d has %d fields", i+2, len(record))
+			return
+		}
+		// a few entries list more than one block
+		for _, block := range strings.Split(record[0], ",") {
+			var prefix netip.Prefix
+			prefix, err = netip.ParsePrefix(strings.TrimSpace(block))
+			if err != nil {
+				err = fmt.Errorf("registry line %d: %w", i+2, err)
+				return
+			}
+			terminated := record[4]
+			if terminated == "N/A" {
+				terminated = ""
+			}
+			entries = append(entries, SpecialPurpose{
+				Prefix:             prefix,
+				Name:               strings.Trim(record[1], `"`),
+				RFC:                record[2],
+				Allocated:          record[3],
+				Terminated:         terminated,
+				Source:             flag(record[5]),
+				Destination:        flag(record[6]),
+				Forwardable:        flag(record[7]),
+				GloballyReachable:  flag(record[8]),
+				ReservedByProtocol: flag(record[9]),
+			})
+		}
+	}
+
+	return
+}
+
+// parseAddressSpace parse the IPV6 address space registry with prefix, allocation and reference columns
+func parseAddressSpace(data string) (blocks []AddressBlock, err error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return
+	}
+	for i, record := range records[1:] {
+		if len(record) != 3 {
+			err = fmt.Errorf("registry line %d has %d fields", i+2, len(record))
+			return
+		}
+		var prefix netip.Prefix
+		prefix, err = netip.ParsePrefix(record[0])
+		if err != nil {
+			err = fmt.Errorf("registry line %d: %w", i+2, err)
+			return
+		}
+		blocks = append(blocks, AddressBlock{Prefix: prefix, Name: record[1], RFC: record[2]})
+	}
+
+	return
+}
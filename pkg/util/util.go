@@ -9,7 +9,7 @@ import (
 const (
 	// GlobalUnicast IPV6 type
 	GlobalUnicast = iota
-	// UniqueLocal IPV6 type
+	// UniqueLocal IPV6 unique local type for fc00::/7, which was reported as Private before
 	UniqueLocal
 	// LinkLocalUnicast IPV6 type
 	LinkLocalUnicast
@@ -21,7 +21,7 @@ const (
 	InterfaceLocalMulticast
 	// LinkLocalMulticast IPV6 type
 	LinkLocalMulticast
-	// Private IPV6 type
+	// Private IPV4 private-use type, IPV6 unique local addresses are UniqueLocal
 	Private
 	// Unspecified IPV6 type
 	Unspecified
@@ -51,8 +51,6 @@ func DomainAddresses(domain string) (addresses []netip.Addr, err error) {
 // AddrType get address type as int
 func AddrType(addr netip.Addr) int {
 	switch {
-	// case strings.HasPrefix(addr.StringExpanded(), "fd00"):
-	// 	return UniqueLocal
 	case addr.IsInterfaceLocalMulticast(): // fe80::/10
 		return InterfaceLocalMulticast
 	case addr.IsLinkLocalMulticast(): // ff00::/8 ff02
@@ -61,7 +59,9 @@ func AddrType(addr netip.Addr) int {
 		return LinkLocalUnicast
 	case addr.IsLoopback(): // ::1/128
 		return Loopback
-	case addr.IsPrivate(): // fc00::/7
+	case addr.Is6() && addr.IsPrivate(): // fc00::/7
+		return UniqueLocal
+	case addr.IsPrivate(): // 10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16
 		return Private
 	case addr.IsGlobalUnicast(): // 2001
 		return GlobalUnicast
@@ -108,3 +108,12 @@ func DomainMXRecods(domain string) (mxRecods []*net.MX, err error) {
 
 	return
 }
+
+// UnmapPrefix get an IPV4 mapped IPV6 prefix as IPV4, leaving other prefixes as they are
+func UnmapPrefix(prefix netip.Prefix) netip.Prefix {
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	return prefix
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"net/netip"
 	"testing"
 
 	"github.com/matryer/is"
@@ -23,3 +24,145 @@ func TestLookup(t *testing.T) {
 		}
 	}
 }
+
+func TestIP4SpecialPurpose(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(len(IP4SpecialPurposeRegistry()), 25)
+
+	var tests = []struct {
+		addr   string
+		name   string
+		global bool
+	}{
+		{"100.100.1.1", "Shared Address Space", false},
+		{"192.0.2.10", "Documentation (TEST-NET-1)", false},
+		{"198.19.255.255", "Benchmarking", false},
+		{"169.254.10.1", "Link Local", false},
+		{"192.0.0.9", "Port Control Protocol Anycast", true},
+		{"192.0.0.100", "IETF Protocol Assignments", false},
+		{"0.0.0.0", "This host on this network", false},
+		{"250.1.2.3", "Reserved", false},
+		{"255.255.255.255", "Limited Broadcast", false},
+		{"::ffff:10.1.2.3", "Private-Use", false},
+	}
+	for _, test := range tests {
+		entry, ok := IP4SpecialPurpose(netip.MustParseAddr(test.addr))
+		t.Log(test.addr, entry.Prefix, entry.Name, entry.RFC)
+		is.True(ok)
+		is.Equal(entry.Name, test.name)
+		is.Equal(entry.GloballyReachable, test.global)
+	}
+
+	_, ok := IP4SpecialPurpose(netip.MustParseAddr("8.8.8.8"))
+	is.True(!ok)
+
+	// a prefix only matches entries that hold all of it
+	_, ok = IP4SpecialPurposePrefix(netip.MustParsePrefix("192.0.0.0/16"))
+	is.True(!ok)
+	entry, ok := IP4SpecialPurposePrefix(netip.MustParsePrefix("192.0.0.0/29"))
+	is.True(ok)
+	is.Equal(entry.Name, "IPv4 Service Continuity Prefix")
+	is.True(entry.Source && entry.Forwardable && !entry.ReservedByProtocol)
+}
+
+func TestIP6SpecialPurpose(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		addr   string
+		name   string
+		prefix string
+	}{
+		{"2001:db8::1", "Documentation", "2001:db8::/32"},
+		{"3fff:1::1", "Documentation", "3fff::/20"},
+		{"64:ff9b::808:808", "IPv4-IPv6 Translat.", "64:ff9b::/96"},
+		{"100::1", "Discard-Only Address Block", "100::/64"},
+		{"2002:c000:204::1", "6to4", "2002::/16"},
+		{"2001:0:4136:e378::1", "TEREDO", "2001::/32"},
+		{"2001:1::1", "Port Control Protocol Anycast", "2001:1::1/128"},
+		{"2001:1::9", "IETF Protocol Assignments", "2001::/23"},
+		{"2001:20::1", "ORCHIDv2", "2001:20::/28"},
+		{"fd12:3456::1", "Unique-Local", "fc00::/7"},
+		{"fe80::1", "Link-Local Unicast", "fe80::/10"},
+		{"::ffff:10.1.2.3", "IPv4-mapped Address", "::ffff:0.0.0.0/96"},
+		{"::1", "Loopback Address", "::1/128"},
+	}
+	for _, test := range tests {
+		entry, ok := IP6SpecialPurpose(netip.MustParseAddr(test.addr))
+		t.Log(test.addr, entry.Prefix, entry.Name, entry.RFC)
+		is.True(ok)
+		is.Equal(entry.Name, test.name)
+		is.Equal(entry.Prefix.String(), test.prefix)
+	}
+
+	_, ok := IP6SpecialPurpose(netip.MustParseAddr("2600::1"))
+	is.True(!ok)
+	_, ok = IP6SpecialPurpose(netip.MustParseAddr("10.1.2.3"))
+	is.True(!ok)
+
+	entry, ok := AddrSpecialPurpose(netip.MustParseAddr("10.1.2.3"))
+	is.True(ok)
+	is.Equal(entry.Name, "Private-Use")
+
+	entry, ok = IP6SpecialPurposePrefix(netip.MustParsePrefix("2001:db8:1::/48"))
+	is.True(ok)
+	is.True(!entry.GloballyReachable && !entry.Forwardable)
+	// footnoted N/A is not reachable
+	entry, _ = IP6SpecialPurposePrefix(netip.MustParsePrefix("2002::/16"))
+	is.True(entry.Forwardable && !entry.GloballyReachable)
+}
+
+func TestIP6AddressSpace(t *testing.T) {
+	is := is.New(t)
+
+	var tests = []struct {
+		addr string
+		name string
+		kind int
+	}{
+		{"2600::1", "Global Unicast", GlobalUnicast},
+		{"fd00::1", "Unique Local Unicast", UniqueLocal},
+		{"fc00::1", "Unique Local Unicast", UniqueLocal},
+		{"fe80::1", "Link-Scoped Unicast", LinkLocalUnicast},
+		{"ff05::2", "Multicast", Multicast},
+		{"::1", "Reserved by IETF", Loopback},
+	}
+	for _, test := range tests {
+		addr := netip.MustParseAddr(test.addr)
+		block, ok := IP6AddressSpace(addr)
+		t.Log(test.addr, block.Prefix, block.Name)
+		is.True(ok)
+		is.Equal(block.Name, test.name)
+		is.Equal(AddrType(addr), test.kind)
+	}
+	is.Equal(AddrType(netip.MustParseAddr("192.168.1.1")), Private)
+}
+
+func TestUnmapPrefix(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(UnmapPrefix(netip.MustParsePrefix("::ffff:10.0.0.0/104")), netip.MustParsePrefix("10.0.0.0/8"))
+	is.Equal(UnmapPrefix(netip.MustParsePrefix("::ffff:0.0.0.0/96")), netip.MustParsePrefix("0.0.0.0/0"))
+	// shorter than the mapped prefix so not an IPV4 network
+	is.Equal(UnmapPrefix(netip.MustParsePrefix("::ffff:0.0.0.0/95")), netip.MustParsePrefix("::ffff:0.0.0.0/95"))
+	is.Equal(UnmapPrefix(netip.MustParsePrefix("2001:db8::/32")), netip.MustParsePrefix("2001:db8::/32"))
+	is.Equal(UnmapPrefix(netip.MustParsePrefix("10.0.0.0/8")), netip.MustParsePrefix("10.0.0.0/8"))
+}
+
+func TestRegistriesParse(t *testing.T) {
+	is := is.New(t)
+
+	for _, data := range []string{ip4SpecialRegistryCSV, ip6SpecialRegistryCSV} {
+		entries, err := parseSpecialRegistry(data)
+		is.NoErr(err)
+		is.True(len(entries) > 0)
+	}
+	blocks, err := parseAddressSpace(ip6AddressSpaceCSV)
+	is.NoErr(err)
+	is.True(len(blocks) > 0)
+
+	_, err = parseSpecialRegistry("Address Block,Name\nnot a prefix,Broken\n")
+	t.Log(err)
+	is.True(err != nil)
+}